@@ -1,11 +1,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"math"
 	"strconv"
+	"sync"
 	"time"
 
 	"better-fantasy/models"
@@ -14,10 +16,10 @@ import (
 )
 
 const (
-	fixturesApi       = "https://fantasy.premierleague.com/api/fixtures/"
-	statsApi          = "https://fantasy.premierleague.com/api/bootstrap-static/"
-	playerFixturesApi = "https://fantasy.premierleague.com/api/element-summary/"
-	picksApi          = "https://fantasy.premierleague.com/api/entry/%d/event/%d/picks/"
+	fixturesPath       = "fixtures/"
+	statsPath          = "bootstrap-static/"
+	playerFixturesPath = "element-summary/%d/"
+	picksPath          = "entry/%d/event/%d/picks/"
 )
 
 type apiTeam struct {
@@ -87,6 +89,7 @@ type apiPlayerFixturesAndHistory struct {
 type apiPlayerHistory struct {
 	ElementID   int  `json:"element"`
 	FixtureID   int  `json:"fixture"`
+	Round       int  `json:"round"`
 	Minutes     int  `json:"minutes"`
 	TotalPoints int  `json:"total_points"`
 	GoalsScored int  `json:"goals_scored"`
@@ -96,15 +99,60 @@ type apiPlayerHistory struct {
 	Bonus       int  `json:"bonus"`
 	CleanSheets int  `json:"clean_sheets"`
 	WasHome     bool `json:"was_home"`
+
+	GoalsConceded         int `json:"goals_conceded"`
+	Saves                 int `json:"saves"`
+	PenaltiesSaved        int `json:"penalties_saved"`
+	PenaltiesMissed       int `json:"penalties_missed"`
+	OwnGoals              int `json:"own_goals"`
+	DefensiveContribution int `json:"defensive_contribution"`
+	Starts                int `json:"starts"`
 }
 
 type apiFixture struct {
-	ID                 int `json:"id"`
-	AwayTeamID         int `json:"team_a"`
-	HomeTeamID         int `json:"team_h"`
-	EventID            int `json:"event"`
-	AwayTeamDifficulty int `json:"team_a_difficulty"`
-	HomeTeamDifficulty int `json:"team_h_difficulty"`
+	ID                 int  `json:"id"`
+	AwayTeamID         int  `json:"team_a"`
+	HomeTeamID         int  `json:"team_h"`
+	EventID            *int `json:"event"` // null until the fixture is scheduled
+	AwayTeamDifficulty int  `json:"team_a_difficulty"`
+	HomeTeamDifficulty int  `json:"team_h_difficulty"`
+	// kickoff_time and the scores are null until they're known, which
+	// leaves them zero
+	KickoffTime   time.Time       `json:"kickoff_time"`
+	Started       bool            `json:"started"`
+	Finished      bool            `json:"finished"`
+	Minutes       int             `json:"minutes"`
+	HomeTeamScore int             `json:"team_h_score"`
+	AwayTeamScore int             `json:"team_a_score"`
+	Stats         apiFixtureStats `json:"stats"`
+}
+
+// apiFixtureStat is every player's value of one stat in a fixture, by side.
+type apiFixtureStat struct {
+	Identifier string                `json:"identifier"`
+	Away       []apiFixtureStatValue `json:"a"`
+	Home       []apiFixtureStatValue `json:"h"`
+}
+
+type apiFixtureStatValue struct {
+	Value   int `json:"value"`
+	Element int `json:"element"`
+}
+
+type apiFixtureStats []apiFixtureStat
+
+// toModels flattens the stats into one per player per stat.
+func (s apiFixtureStats) toModels() []models.FixtureStat {
+	stats := make([]models.FixtureStat, 0)
+	for _, stat := range s {
+		for _, value := range stat.Home {
+			stats = append(stats, models.FixtureStat{Identifier: stat.Identifier, PlayerID: models.PlayerID(value.Element), Home: true, Value: value.Value})
+		}
+		for _, value := range stat.Away {
+			stats = append(stats, models.FixtureStat{Identifier: stat.Identifier, PlayerID: models.PlayerID(value.Element), Home: false, Value: value.Value})
+		}
+	}
+	return stats
 }
 
 type apiFixtures []apiFixture
@@ -131,11 +179,30 @@ type Data struct {
 	Teams        []*models.Team
 	Players      []models.Player
 	ManagerPicks []models.ManagerPick
+	// ManagerGameweeks holds the bank of the manager whose picks were
+	// fetched
+	ManagerGameweeks []models.ManagerGameweek
 }
 
 type FetchOptions struct {
 	ManagerID int
 	Gameweek  int
+	// Client defaults to a live client for DefaultBaseURL
+	Client *Client
+	// Concurrency is the number of player histories requested at once,
+	// defaulting to DefaultConcurrency
+	Concurrency int
+	// Known holds previously stored players. Histories are only requested for
+	// players missing from it or whose minutes, total points, cost or news
+	// have since changed; the rest are returned with a nil History.
+	Known map[models.PlayerID]models.Player
+}
+
+const DefaultConcurrency = 8
+
+type playerResult struct {
+	player models.Player
+	err    error
 }
 
 // func (d *Data) FixturesByGameWeek(gameweek int) []models.Fixture {
@@ -157,14 +224,14 @@ type FetchOptions struct {
 // 	return nil
 // }
 
-// func (d *Data) CurrentGameweek() *models.Gameweek {
-// 	for _, gameweek := range d.Gameweeks {
-// 		if gameweek.IsCurrent {
-// 			return &gameweek
-// 		}
-// 	}
-// 	return nil
-// }
+func (d *Data) CurrentGameweek() *models.Gameweek {
+	for _, gameweek := range d.Gameweeks {
+		if gameweek.IsCurrent {
+			return &gameweek
+		}
+	}
+	return nil
+}
 
 // func (d *Data) PlayerType(pt string) *models.PlayerType {
 // 	for _, playerType := range d.PlayerTypes {
@@ -233,19 +300,29 @@ type FetchOptions struct {
 // 	}
 // }
 
-func FetchData(options FetchOptions) (*Data, error) {
+func FetchData(ctx context.Context, options FetchOptions) (*Data, error) {
 	data := &Data{}
 
-	statsApiBody, err := getJsonBody(statsApi)
+	client := options.Client
+	if client == nil {
+		client = NewClient(DefaultBaseURL, nil)
+	}
+
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	statsApiBody, err := client.get(ctx, statsPath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	var statsResp apiStats
 	if err := json.Unmarshal(statsApiBody, &statsResp); err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	var currentGameweekID models.GameweekID
+	var currentGameweekID, nextGameweekID models.GameweekID
 
 	gameweeksByID := make(map[models.GameweekID]*models.Gameweek, 0)
 	for _, apiEvent := range statsResp.Events {
@@ -253,6 +330,9 @@ func FetchData(options FetchOptions) (*Data, error) {
 		if apiEvent.IsCurrent {
 			currentGameweekID = gameweekID
 		}
+		if apiEvent.IsNext {
+			nextGameweekID = gameweekID
+		}
 		gameweek := &models.Gameweek{
 			ID:              gameweekID,
 			Name:            apiEvent.Name,
@@ -265,6 +345,9 @@ func FetchData(options FetchOptions) (*Data, error) {
 		gameweeksByID[gameweekID] = gameweek
 		data.Gameweeks = append(data.Gameweeks, *gameweek)
 	}
+	if nextGameweekID == 0 {
+		nextGameweekID = currentGameweekID + 1
+	}
 
 	var teams []*models.Team
 	teamsByID := make(map[models.TeamID]*models.Team, 0)
@@ -295,43 +378,68 @@ func FetchData(options FetchOptions) (*Data, error) {
 		data.PlayerTypes = append(data.PlayerTypes, newType)
 	}
 
-	playerCount := len(statsResp.Elements)
-	playersChannel := make(chan models.Player, playerCount)
-	errorsChannel := make(chan error, playerCount)
-
 	teamPlayersByID := make(map[models.TeamID][]models.Player, 0)
 	allPlayers := make([]models.Player, 0)
 
-	for _, apiPlayer := range statsResp.Elements {
+	// a fixed pool of workers requests player histories so that we never
+	// have more than `concurrency` requests in flight
+	jobs := make(chan apiElement)
+	results := make(chan playerResult)
+
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
 		go func() {
-			newPlayer, err := newPlayer(apiPlayer, teamsByID, playerTypesByID)
-			if err != nil {
-				errorsChannel <- err
-				return
+			defer workers.Done()
+			for apiPlayer := range jobs {
+				fetchHistory := hasChanged(apiPlayer, options.Known)
+				newPlayer, err := newPlayer(ctx, client, apiPlayer, teamsByID, playerTypesByID, nextGameweekID, fetchHistory)
+				if known, ok := options.Known[newPlayer.ID]; ok && !fetchHistory {
+					// appearances are counted from the history, which is unchanged
+					newPlayer.Stats.MatchesPlayed = known.Stats.MatchesPlayed
+				}
+				results <- playerResult{player: newPlayer, err: err}
 			}
-			playersChannel <- newPlayer
 		}()
 	}
 
-	var errors error
-	for i := 0; i < playerCount; i++ {
-		select {
-		case player := <-playersChannel:
-			allPlayers = append(allPlayers, player)
-			teamPlayersByID[player.Team.ID] = append(
-				teamPlayersByID[models.TeamID(player.Team.ID)],
-				player,
-			)
-		case err := <-errorsChannel:
-			errors = multierror.Append(errors, err)
+	go func() {
+		defer close(jobs)
+		for _, apiPlayer := range statsResp.Elements {
+			select {
+			case jobs <- apiPlayer:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	var playerErrors error
+	for result := range results {
+		if result.err != nil {
+			playerErrors = multierror.Append(playerErrors, result.err)
+			continue
+		}
+		if currentGameweek, ok := gameweeksByID[currentGameweekID]; ok {
+			result.player.MostCaptained = result.player.ID == currentGameweek.MostCaptainedID
 		}
+		allPlayers = append(allPlayers, result.player)
+		teamPlayersByID[result.player.Team.ID] = append(
+			teamPlayersByID[models.TeamID(result.player.Team.ID)],
+			result.player,
+		)
 	}
-	if errors != nil {
-		return &Data{}, fmt.Errorf("there was a problem building players: %w", errors)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if playerErrors != nil {
+		return &Data{}, fmt.Errorf("there was a problem building players: %w", playerErrors)
 	}
-
-	close(playersChannel)
-	close(errorsChannel)
 
 	data.Players = allPlayers
 
@@ -339,14 +447,14 @@ func FetchData(options FetchOptions) (*Data, error) {
 		team.Players = teamPlayersByID[team.ID]
 	}
 
-	fixturesBody, err := getJsonBody(fixturesApi)
+	fixturesBody, err := client.get(ctx, fixturesPath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	var apiFixtures apiFixtures
 	if err := json.Unmarshal(fixturesBody, &apiFixtures); err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	fixtures := make([]*models.Fixture, 0)
@@ -354,9 +462,10 @@ func FetchData(options FetchOptions) (*Data, error) {
 		homeTeam := teamsByID[models.TeamID(apiFixture.HomeTeamID)]
 		awayTeam := teamsByID[models.TeamID(apiFixture.AwayTeamID)]
 
-		gameweek, ok := gameweeksByID[models.GameweekID(apiFixture.EventID)]
-		if !ok {
-			continue
+		// unscheduled fixtures are kept without a gameweek
+		var gameweek *models.Gameweek
+		if apiFixture.EventID != nil {
+			gameweek = gameweeksByID[models.GameweekID(*apiFixture.EventID)]
 		}
 
 		newFixture := models.Fixture{
@@ -367,6 +476,13 @@ func FetchData(options FetchOptions) (*Data, error) {
 			HomeTeamDifficulty: apiFixture.HomeTeamDifficulty,
 			AwayTeamDifficulty: apiFixture.AwayTeamDifficulty,
 			DifficultyMajority: abs(apiFixture.HomeTeamDifficulty - apiFixture.AwayTeamDifficulty),
+			KickoffTime:        apiFixture.KickoffTime,
+			Started:            apiFixture.Started,
+			Finished:           apiFixture.Finished,
+			Minutes:            apiFixture.Minutes,
+			HomeTeamScore:      apiFixture.HomeTeamScore,
+			AwayTeamScore:      apiFixture.AwayTeamScore,
+			Stats:              apiFixture.Stats.toModels(),
 		}
 		fixtures = append(fixtures, &newFixture)
 
@@ -381,9 +497,7 @@ func FetchData(options FetchOptions) (*Data, error) {
 	data.Fixtures = fixtures
 
 	if options.ManagerID > 0 {
-		endpoint := fmt.Sprintf(picksApi, options.ManagerID, currentGameweekID)
-
-		teamBody, err := getJsonBody(endpoint)
+		teamBody, err := client.get(ctx, fmt.Sprintf(picksPath, options.ManagerID, currentGameweekID))
 		if err != nil {
 			return nil, err
 		}
@@ -402,15 +516,24 @@ func FetchData(options FetchOptions) (*Data, error) {
 				IsViceCaptain: pick.IsViceCaptain,
 			})
 		}
+		data.ManagerGameweeks = append(data.ManagerGameweeks, models.ManagerGameweek{
+			ManagerID:  options.ManagerID,
+			GameweekID: currentGameweekID,
+			Bank:       apiPicks.EntryHistory.Bank / 10,
+		})
 	}
 
 	return data, nil
 }
 
 func newPlayer(
+	ctx context.Context,
+	client *Client,
 	apiPlayer apiElement,
 	teamsByID map[models.TeamID]*models.Team,
 	playerTypesByID map[models.PlayerTypeID]models.PlayerType,
+	nextGameweekID models.GameweekID,
+	fetchHistory bool,
 ) (models.Player, error) {
 	playerForm, err := strconv.ParseFloat(apiPlayer.Form, 32)
 	if err != nil {
@@ -439,24 +562,25 @@ func newPlayer(
 
 	formattedCost := fmt.Sprintf("£%.1fm", float32(apiPlayer.Cost)/float32(10))
 
-	// var chanceOfPlayingThisRound float32
-	// if apiPlayer.ChanceOfPlayingThisRound == nil {
-	// 	chanceOfPlayingThisRound = 1
-	// } else {
-	// 	chanceOfPlayingThisRound = float32(*apiPlayer.ChanceOfPlayingThisRound) / 100
-	// }
-
-	// var chanceOfPlayingNextRound float32
-	// if apiPlayer.ChanceOfPlayingNextRound == nil {
-	// 	chanceOfPlayingNextRound = 1
-	// } else {
-	// 	chanceOfPlayingNextRound = float32(*apiPlayer.ChanceOfPlayingNextRound) / 100
-	// }
-
-	// chanceOfPlaying := map[models.GameweekID]float32{
-	// 	currentGameweekID:     chanceOfPlayingThisRound,
-	// 	currentGameweekID + 1: chanceOfPlayingNextRound, // assumes next round is gameweek ID + 1
-	// }
+	var chanceOfPlayingThisRound float32
+	if apiPlayer.ChanceOfPlayingThisRound == nil {
+		chanceOfPlayingThisRound = 1
+	} else {
+		chanceOfPlayingThisRound = float32(*apiPlayer.ChanceOfPlayingThisRound) / 100
+	}
+
+	var chanceOfPlayingNextRound float32
+	if apiPlayer.ChanceOfPlayingNextRound == nil {
+		chanceOfPlayingNextRound = 1
+	} else {
+		chanceOfPlayingNextRound = float32(*apiPlayer.ChanceOfPlayingNextRound) / 100
+	}
+
+	// "this round" is the next deadline, not the gameweek that is in progress
+	chanceOfPlaying := models.PlayerRoundProbability{
+		nextGameweekID:     chanceOfPlayingThisRound,
+		nextGameweekID + 1: chanceOfPlayingNextRound, // assumes next round is gameweek ID + 1
+	}
 
 	pickedPercentage, err := strconv.ParseFloat(apiPlayer.SelectedByPercent, 32)
 	if err != nil {
@@ -487,21 +611,45 @@ func newPlayer(
 			ICTIndex:      float32(ictIndex),
 			ICTIndexRank:  apiPlayer.ICTIndexRank,
 		},
-		// ChanceOfPlaying:  chanceOfPlaying,
+		ChanceOfPlaying:  chanceOfPlaying,
 		PickedPercentage: float32(pickedPercentage),
+		News:             apiPlayer.News,
+	}
+
+	if !fetchHistory {
+		return newPlayer, nil
 	}
 
-	history, err := requestPlayerHistory(int(newPlayer.ID))
+	history, err := requestPlayerHistory(ctx, client, int(newPlayer.ID))
 	if err != nil {
 		return models.Player{}, err
 	}
 	newPlayer.History = history
+	newPlayer.Stats.MatchesPlayed = float32(newPlayer.Appearances())
 
 	return newPlayer, nil
 }
 
-func requestPlayerHistory(apiPlayerID int) (map[models.FixtureID]models.PlayerFixture, error) {
-	fixturesAndHistoryApiBody, err := getJsonBody(fmt.Sprintf("%s/%d", playerFixturesApi, apiPlayerID))
+// hasChanged reports whether a player's history needs requesting, i.e. the
+// player is new, was stored without the history of the minutes they've
+// played, or has played, scored, changed price or picked up news since they
+// were stored.
+func hasChanged(apiPlayer apiElement, known map[models.PlayerID]models.Player) bool {
+	knownPlayer, ok := known[models.PlayerID(apiPlayer.ID)]
+	if !ok {
+		return true
+	}
+	if knownPlayer.Stats.Minutes > 0 && len(knownPlayer.History) == 0 {
+		return true
+	}
+	return knownPlayer.Stats.Minutes != apiPlayer.Minutes ||
+		knownPlayer.TotalPoints != apiPlayer.TotalPoints ||
+		int(math.Round(float64(knownPlayer.RawCost)*10)) != apiPlayer.Cost ||
+		knownPlayer.News != apiPlayer.News
+}
+
+func requestPlayerHistory(ctx context.Context, client *Client, apiPlayerID int) (map[models.FixtureID]models.PlayerFixture, error) {
+	fixturesAndHistoryApiBody, err := client.get(ctx, fmt.Sprintf(playerFixturesPath, apiPlayerID))
 	if err != nil {
 		return nil, err
 	}
@@ -514,6 +662,7 @@ func requestPlayerHistory(apiPlayerID int) (map[models.FixtureID]models.PlayerFi
 		fixturesToPlayerFixtures[models.FixtureID(fixture.FixtureID)] = models.PlayerFixture{
 			FixtureID:   models.FixtureID(fixture.FixtureID),
 			PlayerID:    models.PlayerID(fixture.ElementID),
+			GameweekID:  models.GameweekID(fixture.Round),
 			Minutes:     fixture.Minutes,
 			Played:      fixture.Minutes > 0,
 			Points:      fixture.TotalPoints,
@@ -524,13 +673,38 @@ func requestPlayerHistory(apiPlayerID int) (map[models.FixtureID]models.PlayerFi
 			Bonus:       fixture.Bonus,
 			CleanSheet:  fixture.CleanSheets > 0,
 			WasHome:     fixture.WasHome,
+
+			GoalsConceded:         fixture.GoalsConceded,
+			Saves:                 fixture.Saves,
+			PenaltiesSaved:        fixture.PenaltiesSaved,
+			PenaltiesMissed:       fixture.PenaltiesMissed,
+			OwnGoals:              fixture.OwnGoals,
+			DefensiveContribution: fixture.DefensiveContribution,
+			Starts:                fixture.Starts,
 		}
 	}
 
 	return fixturesToPlayerFixtures, nil
 }
 
-func backoff(f func() error, retries int, baseInterval time.Duration) error {
+// retryableError marks a failed attempt as worth retrying, optionally after a
+// server requested delay.
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// backoff retries f while it returns a retryableError, waiting the error's
+// retryAfter or else a fibonacci multiple of baseInterval between attempts.
+func backoff(ctx context.Context, f func() error, retries int, baseInterval time.Duration) error {
 	var err error
 	var fib1, fib2 int = 0, 1
 	for i := 0; i <= retries; i++ {
@@ -538,35 +712,26 @@ func backoff(f func() error, retries int, baseInterval time.Duration) error {
 		if err == nil {
 			return nil
 		}
+		var retryable *retryableError
+		if !errors.As(err, &retryable) {
+			return err
+		}
 		nextInterval := baseInterval * time.Duration(fib1)
+		if retryable.retryAfter > 0 {
+			nextInterval = retryable.retryAfter
+		}
 		fib1, fib2 = fib2, fib1+fib2
-		time.Sleep(nextInterval)
+		timer := time.NewTimer(nextInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
 	}
 	return err
 }
 
-func getJsonBody(endpoint string) ([]byte, error) {
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode == http.StatusTooManyRequests {
-		backoff(func() error {
-			resp, err = http.Get(endpoint)
-			if resp.StatusCode == http.StatusTooManyRequests {
-				return fmt.Errorf("status was not ok (status: %d)", resp.StatusCode)
-			}
-			return nil
-		}, 15, time.Second*1)
-	}
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	resp.Body.Close()
-	return body, nil
-}
-
 func abs(x int) int {
 	if x < 0 {
 		return -x
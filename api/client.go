@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://fantasy.premierleague.com/api/"
+)
+
+// Transport fetches the raw response body for a path relative to the API root,
+// e.g. "bootstrap-static/" or "element-summary/123/".
+type Transport interface {
+	Get(ctx context.Context, path string) ([]byte, error)
+}
+
+// HTTPTransport requests paths from a live FPL API. Every attempt, including
+// retries, waits on Limiter when one is set.
+type HTTPTransport struct {
+	BaseURL string
+	Client  *http.Client
+	Limiter *RateLimiter
+}
+
+func (t *HTTPTransport) Get(ctx context.Context, path string) ([]byte, error) {
+	endpoint := strings.TrimSuffix(t.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+
+	httpClient := t.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	var body []byte
+	err := backoff(ctx, func() error {
+		if err := t.Limiter.Wait(ctx); err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			// only rate limited requests are retried
+			return &retryableError{
+				err:        fmt.Errorf("status was not ok (endpoint: %s, status: %d)", endpoint, resp.StatusCode),
+				retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			}
+		default:
+			return fmt.Errorf("status was not ok (endpoint: %s, status: %d)", endpoint, resp.StatusCode)
+		}
+		body, err = io.ReadAll(resp.Body)
+		return err
+	}, 15, time.Second*1)
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date, returning 0 if it is missing or invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(0, date.Sub(now))
+	}
+	return 0
+}
+
+// ReplayTransport serves paths from a directory written by a Recorder, so no
+// network access is needed.
+type ReplayTransport struct {
+	Dir string
+}
+
+func (t *ReplayTransport) Get(ctx context.Context, path string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := os.ReadFile(recordingPath(t.Dir, path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no recorded response for '%s' in '%s'", path, t.Dir)
+		}
+		return nil, err
+	}
+	return body, nil
+}
+
+// Recorder saves every response body it is given beneath Dir, using the same
+// layout ReplayTransport reads from.
+type Recorder struct {
+	Dir string
+}
+
+func (r *Recorder) Record(path string, body []byte) error {
+	outputFile := recordingPath(r.Dir, path)
+	if err := os.MkdirAll(filepath.Dir(outputFile), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(outputFile, body, 0644)
+}
+
+type Client struct {
+	Transport Transport
+	Recorder  *Recorder
+}
+
+// NewClient returns a client for the live API at baseURL (DefaultBaseURL if
+// empty). A nil limiter doesn't limit requests.
+func NewClient(baseURL string, limiter *RateLimiter) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		Transport: &HTTPTransport{
+			BaseURL: baseURL,
+			Limiter: limiter,
+		},
+	}
+}
+
+// NewRecordingClient returns a live client which also saves every response to
+// dir.
+func NewRecordingClient(baseURL string, limiter *RateLimiter, dir string) *Client {
+	client := NewClient(baseURL, limiter)
+	client.Recorder = &Recorder{Dir: dir}
+	return client
+}
+
+// NewReplayClient returns a client which serves every request from dir.
+func NewReplayClient(dir string) *Client {
+	return &Client{
+		Transport: &ReplayTransport{Dir: dir},
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
+	body, err := c.Transport.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if c.Recorder != nil {
+		if err := c.Recorder.Record(path, body); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// recordingPath maps an API path to a file, e.g. "element-summary/123/"
+// becomes "<dir>/element-summary/123.json".
+func recordingPath(dir string, path string) string {
+	return filepath.Join(dir, filepath.FromSlash(strings.Trim(path, "/"))+".json")
+}
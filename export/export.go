@@ -0,0 +1,391 @@
+package export
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/store"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+)
+
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case CSV, JSON, NDJSON:
+		return Format(format), nil
+	}
+	return "", fmt.Errorf("unknown export format '%s' (expected csv, json or ndjson)", format)
+}
+
+type Options struct {
+	Dir    string
+	Format Format
+	// Gameweek limits fixtures, player fixtures and picks to one gameweek and
+	// takes player values from that gameweek's snapshot where there is one.
+	// 0 exports everything as currently stored.
+	Gameweek int
+}
+
+// The row types below define the exported columns, named by their json tags
+// in the order they are declared. Only ever append columns so downstream
+// scripts keep working.
+
+type playerRow struct {
+	ID               models.PlayerID     `json:"id"`
+	Name             string              `json:"name"`
+	TeamID           models.TeamID       `json:"team_id"`
+	Team             string              `json:"team"`
+	PositionID       models.PlayerTypeID `json:"position_id"`
+	Position         string              `json:"position"`
+	Cost             float32             `json:"cost"`
+	Form             float32             `json:"form"`
+	PointsPerGame    float32             `json:"points_per_game"`
+	TotalPoints      int                 `json:"total_points"`
+	Minutes          int                 `json:"minutes"`
+	Goals            int                 `json:"goals"`
+	Assists          int                 `json:"assists"`
+	Conceded         int                 `json:"conceded"`
+	CleanSheets      int                 `json:"clean_sheets"`
+	YellowCards      int                 `json:"yellow_cards"`
+	RedCards         int                 `json:"red_cards"`
+	Bonus            int                 `json:"bonus"`
+	Starts           int                 `json:"starts"`
+	AverageStarts    float32             `json:"average_starts"`
+	MatchesPlayed    float32             `json:"matches_played"`
+	ICTIndex         float32             `json:"ict_index"`
+	ICTIndexRank     int                 `json:"ict_index_rank"`
+	PickedPercentage float32             `json:"picked_percentage"`
+	News             string              `json:"news"`
+}
+
+type playerFixtureRow struct {
+	FixtureID   models.FixtureID  `json:"fixture_id"`
+	PlayerID    models.PlayerID   `json:"player_id"`
+	GameweekID  models.GameweekID `json:"gameweek_id"`
+	Minutes     int               `json:"minutes"`
+	Played      bool              `json:"played"`
+	Points      int               `json:"points"`
+	GoalsScored int               `json:"goals_scored"`
+	Assists     int               `json:"assists"`
+	YellowCards int               `json:"yellow_cards"`
+	RedCards    int               `json:"red_cards"`
+	Bonus       int               `json:"bonus"`
+	CleanSheet  bool              `json:"clean_sheet"`
+	WasHome     bool              `json:"was_home"`
+
+	GoalsConceded         int `json:"goals_conceded"`
+	Saves                 int `json:"saves"`
+	PenaltiesSaved        int `json:"penalties_saved"`
+	PenaltiesMissed       int `json:"penalties_missed"`
+	OwnGoals              int `json:"own_goals"`
+	DefensiveContribution int `json:"defensive_contribution"`
+	Starts                int `json:"starts"`
+}
+
+type fixtureRow struct {
+	ID                 models.FixtureID  `json:"id"`
+	GameweekID         models.GameweekID `json:"gameweek_id"`
+	HomeTeamID         models.TeamID     `json:"home_team_id"`
+	HomeTeam           string            `json:"home_team"`
+	AwayTeamID         models.TeamID     `json:"away_team_id"`
+	AwayTeam           string            `json:"away_team"`
+	HomeTeamDifficulty int               `json:"home_team_difficulty"`
+	AwayTeamDifficulty int               `json:"away_team_difficulty"`
+	DifficultyMajority int               `json:"difficulty_majority"`
+	// KickoffTime is RFC 3339, empty until it's known.
+	KickoffTime   string `json:"kickoff_time"`
+	Started       bool   `json:"started"`
+	Finished      bool   `json:"finished"`
+	Minutes       int    `json:"minutes"`
+	HomeTeamScore int    `json:"home_team_score"`
+	AwayTeamScore int    `json:"away_team_score"`
+}
+
+type teamRow struct {
+	ID        models.TeamID `json:"id"`
+	Name      string        `json:"name"`
+	ShortName string        `json:"short_name"`
+}
+
+type managerPickRow struct {
+	ManagerID     int               `json:"manager_id"`
+	GameweekID    models.GameweekID `json:"gameweek_id"`
+	PlayerID      int               `json:"player_id"`
+	IsCaptain     bool              `json:"is_captain"`
+	IsViceCaptain bool              `json:"is_vice_captain"`
+}
+
+// Export writes players, player_fixtures, fixtures, teams and manager_picks to
+// options.Dir in the chosen format, returning the files written.
+func Export(reader store.ReadData, options Options) ([]string, error) {
+	if err := os.MkdirAll(options.Dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	fixtures, err := exportedFixtures(reader, options.Gameweek)
+	if err != nil {
+		return nil, err
+	}
+	fixturesByID := make(map[models.FixtureID]*models.Fixture, len(fixtures))
+	fixtureRows := make([]fixtureRow, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		fixturesByID[fixture.ID] = fixture
+		fixtureRows = append(fixtureRows, fixtureRow{
+			ID:                 fixture.ID,
+			GameweekID:         fixture.Gameweek.ID,
+			HomeTeamID:         fixture.HomeTeam.ID,
+			HomeTeam:           fixture.HomeTeam.Name,
+			AwayTeamID:         fixture.AwayTeam.ID,
+			AwayTeam:           fixture.AwayTeam.Name,
+			HomeTeamDifficulty: fixture.HomeTeamDifficulty,
+			AwayTeamDifficulty: fixture.AwayTeamDifficulty,
+			DifficultyMajority: fixture.DifficultyMajority,
+			KickoffTime:        kickoffTime(fixture),
+			Started:            fixture.Started,
+			Finished:           fixture.Finished,
+			Minutes:            fixture.Minutes,
+			HomeTeamScore:      fixture.HomeTeamScore,
+			AwayTeamScore:      fixture.AwayTeamScore,
+		})
+	}
+
+	players, err := reader.GetPlayers(store.PlayerFilter{})
+	if err != nil {
+		return nil, err
+	}
+	snapshotsByID := make(map[models.PlayerID]models.PlayerSnapshot, 0)
+	if options.Gameweek > 0 {
+		snapshots, err := reader.GetGameweekSnapshots(options.Gameweek)
+		if err != nil {
+			return nil, err
+		}
+		for _, snapshot := range snapshots {
+			snapshotsByID[snapshot.PlayerID] = snapshot
+		}
+	}
+
+	playerRows := make([]playerRow, 0, len(players))
+	playerFixtureRows := make([]playerFixtureRow, 0)
+	for _, player := range players {
+		if snapshot, ok := snapshotsByID[player.ID]; ok {
+			player.Form = snapshot.Form
+			player.PointsPerGame = snapshot.PointsPerGame
+			player.TotalPoints = snapshot.TotalPoints
+			player.RawCost = snapshot.RawCost
+			player.Stats = snapshot.Stats
+			player.PickedPercentage = snapshot.PickedPercentage
+			player.News = snapshot.News
+		}
+		playerRows = append(playerRows, newPlayerRow(player))
+
+		for _, fixture := range player.SortedHistory() {
+			if _, ok := fixturesByID[fixture.FixtureID]; !ok {
+				continue
+			}
+			playerFixtureRows = append(playerFixtureRows, playerFixtureRow{
+				FixtureID:   fixture.FixtureID,
+				PlayerID:    fixture.PlayerID,
+				GameweekID:  fixturesByID[fixture.FixtureID].Gameweek.ID,
+				Minutes:     fixture.Minutes,
+				Played:      fixture.Played,
+				Points:      fixture.Points,
+				GoalsScored: fixture.GoalsScored,
+				Assists:     fixture.Assists,
+				YellowCards: fixture.YellowCards,
+				RedCards:    fixture.RedCards,
+				Bonus:       fixture.Bonus,
+				CleanSheet:  fixture.CleanSheet,
+				WasHome:     fixture.WasHome,
+
+				GoalsConceded:         fixture.GoalsConceded,
+				Saves:                 fixture.Saves,
+				PenaltiesSaved:        fixture.PenaltiesSaved,
+				PenaltiesMissed:       fixture.PenaltiesMissed,
+				OwnGoals:              fixture.OwnGoals,
+				DefensiveContribution: fixture.DefensiveContribution,
+				Starts:                fixture.Starts,
+			})
+		}
+	}
+
+	teams, err := reader.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+	teamRows := make([]teamRow, 0, len(teams))
+	for _, team := range teams {
+		teamRows = append(teamRows, teamRow{
+			ID:        team.ID,
+			Name:      team.Name,
+			ShortName: team.ShortName,
+		})
+	}
+
+	picks, err := reader.GetManagerPicks(0, options.Gameweek)
+	if err != nil {
+		return nil, err
+	}
+	pickRows := make([]managerPickRow, 0, len(picks))
+	for _, pick := range picks {
+		pickRows = append(pickRows, managerPickRow{
+			ManagerID:     pick.ManagerID,
+			GameweekID:    pick.GameweekID,
+			PlayerID:      pick.PlayerID,
+			IsCaptain:     pick.IsCaptain,
+			IsViceCaptain: pick.IsViceCaptain,
+		})
+	}
+
+	tables := []struct {
+		name string
+		rows any
+	}{
+		{"players", playerRows},
+		{"player_fixtures", playerFixtureRows},
+		{"fixtures", fixtureRows},
+		{"teams", teamRows},
+		{"manager_picks", pickRows},
+	}
+
+	files := make([]string, 0, len(tables))
+	for _, table := range tables {
+		file := filepath.Join(options.Dir, table.name+"."+string(options.Format))
+		if err := writeRows(file, options.Format, table.rows); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+func exportedFixtures(reader store.ReadData, gameweek int) ([]*models.Fixture, error) {
+	if gameweek > 0 {
+		return reader.GetFixtures(gameweek, gameweek)
+	}
+	gameweeks, err := reader.GetGameweeks()
+	if err != nil || len(gameweeks) == 0 {
+		return nil, err
+	}
+	return reader.GetFixtures(int(gameweeks[0].ID), int(gameweeks[len(gameweeks)-1].ID))
+}
+
+func kickoffTime(fixture *models.Fixture) string {
+	if fixture.KickoffTime.IsZero() {
+		return ""
+	}
+	return fixture.KickoffTime.UTC().Format(time.RFC3339)
+}
+
+func newPlayerRow(player models.Player) playerRow {
+	row := playerRow{
+		ID:               player.ID,
+		Name:             player.Name,
+		PositionID:       player.Type.ID,
+		Position:         player.Type.ShortName,
+		Cost:             player.RawCost,
+		Form:             player.Form,
+		PointsPerGame:    player.PointsPerGame,
+		TotalPoints:      player.TotalPoints,
+		Minutes:          player.Stats.Minutes,
+		Goals:            player.Stats.Goals,
+		Assists:          player.Stats.Assists,
+		Conceded:         player.Stats.Conceded,
+		CleanSheets:      player.Stats.CleanSheets,
+		YellowCards:      player.Stats.YellowCards,
+		RedCards:         player.Stats.RedCards,
+		Bonus:            player.Stats.Bonus,
+		Starts:           player.Stats.Starts,
+		AverageStarts:    player.Stats.AverageStarts,
+		MatchesPlayed:    player.Stats.MatchesPlayed,
+		ICTIndex:         player.Stats.ICTIndex,
+		ICTIndexRank:     player.Stats.ICTIndexRank,
+		PickedPercentage: player.PickedPercentage,
+		News:             player.News,
+	}
+	if player.Team != nil {
+		row.TeamID = player.Team.ID
+		row.Team = player.Team.Name
+	}
+	return row
+}
+
+// writeRows writes a slice of row structs to file.
+func writeRows(file string, format Format, rows any) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	values := reflect.ValueOf(rows)
+
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case NDJSON:
+		encoder := json.NewEncoder(out)
+		for i := 0; i < values.Len(); i++ {
+			if err := encoder.Encode(values.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		writer := csv.NewWriter(out)
+		if err := writer.Write(csvHeader(values.Type().Elem())); err != nil {
+			return err
+		}
+		for i := 0; i < values.Len(); i++ {
+			if err := writer.Write(csvRecord(values.Index(i))); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	return fmt.Errorf("unknown export format '%s'", format)
+}
+
+func csvHeader(rowType reflect.Type) []string {
+	header := make([]string, rowType.NumField())
+	for i := range header {
+		header[i] = rowType.Field(i).Tag.Get("json")
+	}
+	return header
+}
+
+func csvRecord(row reflect.Value) []string {
+	record := make([]string, row.NumField())
+	for i := range record {
+		field := row.Field(i)
+		switch field.Kind() {
+		case reflect.Bool:
+			record[i] = strconv.FormatBool(field.Bool())
+		case reflect.Int, reflect.Int64:
+			record[i] = strconv.FormatInt(field.Int(), 10)
+		case reflect.Float32:
+			record[i] = strconv.FormatFloat(field.Float(), 'f', -1, 32)
+		case reflect.Float64:
+			record[i] = strconv.FormatFloat(field.Float(), 'f', -1, 64)
+		default:
+			record[i] = fmt.Sprint(field.Interface())
+		}
+	}
+	return record
+}
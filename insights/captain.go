@@ -0,0 +1,65 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/squad"
+	"fmt"
+)
+
+func init() {
+	Register(Report{
+		Name:        "captain",
+		Description: "captain picks for the next gameweek from the manager's squad, or every player, in safe or differential mode",
+		Params:      []string{"manager", "captain-mode", "top"},
+		Run: func(i *Insights) ([]printer.List, error) {
+			players, err := i.Players()
+			if err != nil {
+				return nil, err
+			}
+			if i.ManagerID != 0 {
+				managerSquad, err := i.ManagerSquad()
+				if err != nil {
+					return nil, err
+				}
+				players = managerSquad.Players
+			}
+			projected, err := i.projectedPointsOver(1)
+			if err != nil {
+				return nil, err
+			}
+			mode := i.CaptainMode
+			if mode == "" {
+				mode = squad.SafeCaptain
+			}
+
+			options := squad.RankCaptains(players, projected, mode)
+			list := printer.List{
+				Title: fmt.Sprintf("Captains for gameweek %d (%s):", i.Gameweek+1, mode),
+				Items: make([]printer.ListItem, 0),
+			}
+			for _, option := range options[:min(len(options), i.Top)] {
+				list.Items = append(list.Items, printer.ListItem{
+					Format: "%s (%s) (%.1f xP, ±%.1f, ceiling %.1f, %.1f%% EO%s)",
+					Values: []interface{}{
+						option.Player.Name,
+						option.Player.Cost,
+						option.Points,
+						option.StdDev,
+						option.Ceiling,
+						option.EffectiveOwnership,
+						mostCaptainedNote(option.Player),
+					},
+				})
+			}
+			return []printer.List{list}, nil
+		},
+	})
+}
+
+func mostCaptainedNote(player models.Player) string {
+	if player.MostCaptained {
+		return ", most captained"
+	}
+	return ""
+}
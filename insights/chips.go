@@ -0,0 +1,133 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/squad"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(Report{
+		Name:        "chips",
+		Description: "when to play each remaining chip over the rest of the season, with each chip's projected gain per gameweek",
+		Params:      []string{"manager", "budget", "chips"},
+		Run: func(i *Insights) ([]printer.List, error) {
+			managerSquad, err := i.ManagerSquad()
+			if err != nil {
+				return nil, err
+			}
+			players, err := i.Players()
+			if err != nil {
+				return nil, err
+			}
+			playerTypes, err := i.PlayerTypes()
+			if err != nil {
+				return nil, err
+			}
+			last, err := i.LastGameweek()
+			if err != nil {
+				return nil, err
+			}
+			from, to, err := i.gameweeksAhead(last - i.Gameweek)
+			if err != nil {
+				return nil, err
+			}
+			projections, err := i.projectionsOver(last - i.Gameweek)
+			if err != nil {
+				return nil, err
+			}
+			budget, err := i.SquadBudget()
+			if err != nil {
+				return nil, err
+			}
+			teams, err := i.Store.GetTeams()
+			if err != nil {
+				return nil, err
+			}
+			fixtures, err := i.Store.GetFixtures(int(from), int(to))
+			if err != nil {
+				return nil, err
+			}
+
+			gameweeks := make([]models.GameweekID, 0, to-from+1)
+			points := make(map[models.GameweekID]map[models.PlayerID]float32, to-from+1)
+			for gameweekID := from; gameweekID <= to; gameweekID++ {
+				gameweeks = append(gameweeks, gameweekID)
+				points[gameweekID] = make(map[models.PlayerID]float32, len(projections))
+				for _, projection := range projections {
+					points[gameweekID][projection.Player.ID] = projection.PointsIn(gameweekID)
+				}
+			}
+
+			planner := squad.NewChipPlanner(managerSquad, playerTypes, points, budget)
+			planner.Fixtures = models.CountFixtures(fixtures)
+			planner.Teams = teams
+			plan, err := planner.Plan(players, gameweeks, i.Chips)
+			if err != nil {
+				return nil, err
+			}
+
+			byGameweek := printer.List{
+				Title: fmt.Sprintf("Chip gains for gameweeks %d-%d (£%.1fm budget):", from, to, budget),
+				Items: make([]printer.ListItem, 0),
+			}
+			played := make(map[squad.Chip]squad.ChipGameweek, len(i.Chips))
+			for _, gameweek := range plan {
+				gains := make([]string, 0, len(i.Chips))
+				for _, chip := range i.Chips {
+					gains = append(gains, fmt.Sprintf("%s %+.1f", chip, gameweek.Gains[chip]))
+				}
+				play := "no chip"
+				if gameweek.Play != "" {
+					play = "play " + string(gameweek.Play)
+					played[gameweek.Play] = gameweek
+				}
+				byGameweek.Items = append(byGameweek.Items, printer.ListItem{
+					Format: "GW%d: %.1f xP, %s (blank: %s, double: %s) -> %s",
+					Values: []interface{}{
+						gameweek.GameweekID,
+						gameweek.Points,
+						strings.Join(gains, ", "),
+						teamNames(gameweek.Blanks),
+						teamNames(gameweek.Doubles),
+						play,
+					},
+				})
+			}
+
+			recommended := printer.List{
+				Title: "Chip plan:",
+				Items: make([]printer.ListItem, 0),
+			}
+			for _, chip := range i.Chips {
+				gameweek, ok := played[chip]
+				if !ok {
+					recommended.Items = append(recommended.Items, printer.ListItem{
+						Format: "%s: keep, nothing to gain by gameweek %d",
+						Values: []interface{}{chip, to},
+					})
+					continue
+				}
+				recommended.Items = append(recommended.Items, printer.ListItem{
+					Format: "%s: gameweek %d (%+.1f xP)",
+					Values: []interface{}{chip, gameweek.GameweekID, gameweek.Gains[chip]},
+				})
+			}
+			return []printer.List{byGameweek, recommended}, nil
+		},
+	})
+}
+
+// teamNames lists the teams' short names, or "none".
+func teamNames(teams []*models.Team) string {
+	if len(teams) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(teams))
+	for _, team := range teams {
+		names = append(names, team.ShortName)
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,63 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"fmt"
+)
+
+func init() {
+	Register(Report{
+		Name:        "blanks",
+		Description: "the teams that blank or play twice in each gameweek over the horizon, and fixtures still to be scheduled",
+		Params:      []string{"horizon"},
+		Run: func(i *Insights) ([]printer.List, error) {
+			teams, err := i.Store.GetTeams()
+			if err != nil {
+				return nil, err
+			}
+			from, to, err := i.gameweeksAhead(i.Horizon)
+			if err != nil {
+				return nil, err
+			}
+			fixtures, err := i.Store.GetFixtures(int(from), int(to))
+			if err != nil {
+				return nil, err
+			}
+			unscheduled, err := i.Store.GetUnscheduledFixtures()
+			if err != nil {
+				return nil, err
+			}
+
+			counts := models.CountFixtures(fixtures)
+			gameweeks := printer.List{
+				Title: fmt.Sprintf("Blank and double gameweeks %d-%d:", from, to),
+				Items: make([]printer.ListItem, 0),
+			}
+			for gameweekID := from; gameweekID <= to; gameweekID++ {
+				blanks := counts[gameweekID].Blanks(teams)
+				doubles := counts[gameweekID].Doubles(teams)
+				gameweeks.Items = append(gameweeks.Items, printer.ListItem{
+					Format: "GW%d: %d blank (%s), %d double (%s)",
+					Values: []interface{}{gameweekID, len(blanks), teamNames(blanks), len(doubles), teamNames(doubles)},
+				})
+			}
+
+			lists := []printer.List{gameweeks}
+			if len(unscheduled) > 0 {
+				list := printer.List{
+					Title: "Unscheduled fixtures:",
+					Items: make([]printer.ListItem, 0),
+				}
+				for _, fixture := range unscheduled {
+					list.Items = append(list.Items, printer.ListItem{
+						Format: "%s v %s",
+						Values: []interface{}{fixture.HomeTeam.ShortName, fixture.AwayTeam.ShortName},
+					})
+				}
+				lists = append(lists, list)
+			}
+			return lists, nil
+		},
+	})
+}
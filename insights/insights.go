@@ -3,212 +3,168 @@ package insights
 import (
 	"better-fantasy/models"
 	"better-fantasy/printer"
+	"better-fantasy/projection"
+	"better-fantasy/squad"
 	"better-fantasy/store"
+	"better-fantasy/strength"
+	"better-fantasy/ticker"
 	"fmt"
-	"sort"
 )
 
+// DefaultTop is how many players each ranking lists.
+const DefaultTop = 20
+
 type Insights struct {
 	Gameweek int
 	Store    *store.DataStore
+	// Positions limits the rankings to these positions, all when empty.
+	Positions []models.PlayerTypeID
+	Top       int
+	// Horizon is how many gameweeks after the current one to project.
+	Horizon int
+	// Window is how many gameweeks the ticker sorts by and a run lasts.
+	Window int
+	// OfficialDifficulty rates the ticker's fixtures with the official
+	// difficulty instead of team strength.
+	OfficialDifficulty bool
+	// ManagerID and FreeTransfers are for reports on a manager's squad.
+	ManagerID     int
+	FreeTransfers int
+	// Budget is what a squad built from scratch can cost, in £m. 0 uses the
+	// manager's squad value and bank.
+	Budget float32
+	// CaptainMode picks safe or differential captains, safe when empty.
+	CaptainMode squad.CaptainMode
+	// Chips are the chips the manager has left to play.
+	Chips []squad.Chip
+
+	players     []models.Player
+	playerTypes []models.PlayerType
+	projections map[int][]projection.Projection
+	strength    *strength.Ratings
+	// lastGameweek is the season's last gameweek, 0 until it's loaded.
+	lastGameweek int
 }
 
 func NewInsights(store *store.DataStore) *Insights {
 	return &Insights{
 		Gameweek: store.CurrentGameweek(),
 		Store:    store,
+		Top:      DefaultTop,
+		Horizon:  projection.DefaultHorizon,
+		Window:   ticker.DefaultWindow,
+		// only the free transfer the manager gets every gameweek is known
+		FreeTransfers: squad.DefaultFreeTransfers,
+		Chips:         squad.Chips,
 	}
 }
 
+// Analyse prints every default report.
 func (i *Insights) Analyse() error {
-	playersForGameweek, err := i.Store.GetPlayers()
-	if err != nil {
-		return err
+	names := make([]string, 0)
+	for _, report := range Reports() {
+		if report.Default {
+			names = append(names, report.Name)
+		}
 	}
-	playersSlice := make([]models.Player, 0)
-	for _, player := range playersForGameweek {
-		playersSlice = append(playersSlice, player)
-	}
-	highestRankedDefenders := highestRankedDefenders(playersSlice)
-	highestDefenders := printer.List{
-		Title: "Top 20 defenders:",
-		Items: make([]printer.ListItem, 0),
-	}
-	for _, player := range highestRankedDefenders {
-		highestDefenders.Items = append(highestDefenders.Items, printer.ListItem{
-			Format: "%s (%s) (%d pts)",
-			Values: []interface{}{
-				player.Name,
-				player.Cost,
-				player.TotalPoints,
-			},
-		})
-	}
-	printer.PrintList(highestDefenders)
-	fmt.Println()
-	mostAttackingDefenders := mostAttackingDefenders(playersSlice)
-	attackingList := printer.List{
-		Title: "Most attacking defenders (total points g/a):",
-		Items: make([]printer.ListItem, 0),
-	}
-	for _, player := range mostAttackingDefenders {
-		attackingList.Items = append(attackingList.Items, printer.ListItem{
-			Format: "%s (%s) (%.0f)",
-			Values: []interface{}{
-				player.Name,
-				player.Cost,
-				player.AttackingPoints(),
-			},
-		})
-	}
-	printer.PrintList(attackingList)
-	fmt.Println()
-	defendersWithMostCleanSheetPoints := defendersWithMostCleanSheetPoints(playersSlice)
-	cleanSheetList := printer.List{
-		Title: "Defenders with most clean sheets:",
-		Items: make([]printer.ListItem, 0),
-	}
-	for _, player := range defendersWithMostCleanSheetPoints {
-		cleanSheetList.Items = append(cleanSheetList.Items, printer.ListItem{
-			Format: "%s (%s) (%.0f)",
-			Values: []interface{}{
-				player.Name,
-				player.Cost,
-				player.CleanSheets(),
-			},
-		})
-	}
-	printer.PrintList(cleanSheetList)
-	fmt.Println()
-	// bestValueDefenders := bestValueDefenders(playersSlice)
-	// valueList := printer.List{
-	// 	Title: "Best value defenders:",
-	// 	Items: make([]printer.ListItem, 0),
-	// }
-	// for _, player := range bestValueDefenders {
-	// 	valueList.Items = append(valueList.Items, printer.ListItem{
-	// 		Format: "%s (%s) (%.2f)",
-	// 		Values: []interface{}{
-	// 			player.Name,
-	// 			player.Cost,
-	// 			player.AttackingPoints(),
-	// 		},
-	// 	})
-	// }
-	// printer.PrintList(valueList)
-	return nil
+	return i.Run(names)
 }
 
-// func sortPlayersByFormValueDesc(players []models.Player) []models.Player {
-// 	tmp := make([]models.Player, len(players))
-// 	copy(tmp, players)
-// 	sort.Slice(tmp, func(i, j int) bool {
-// 		return tmp[i].FormOverCost() > tmp[j].FormOverCost()
-// 	})
-// 	return tmp
-// }
-
-// func sortPlayersByPointsValueDesc(players []models.Player) []models.Player {
-// 	tmp := make([]models.Player, len(players))
-// 	copy(tmp, players)
-// 	sort.Slice(tmp, func(i, j int) bool {
-// 		return tmp[i].PointsOverCost() > tmp[j].PointsOverCost()
-// 	})
-// 	return tmp
-// }
-
-// func sortPlayersByBonus(players []models.Player) []models.Player {
-// 	tmp := make([]models.Player, len(players))
-// 	copy(tmp, players)
-// 	sort.Slice(tmp, func(i, j int) bool {
-// 		return tmp[i].Stats.Bonus > tmp[j].Stats.Bonus
-// 	})
-// 	return tmp
-// }
+// Run prints the named reports in the order given.
+func (i *Insights) Run(names []string) error {
+	reports := make([]Report, 0, len(names))
+	for _, name := range names {
+		report, ok := ReportByName(name)
+		if !ok {
+			return fmt.Errorf("unknown report '%s'", name)
+		}
+		reports = append(reports, report)
+	}
 
-func highestRankedDefenders(players []models.Player) []models.Player {
-	defenders := make([]models.Player, 0)
-	for _, player := range players {
-		if player.Type.ID == models.PTDefender {
-			defenders = append(defenders, player)
+	for _, report := range reports {
+		lists, err := report.Run(i)
+		if err != nil {
+			return fmt.Errorf("report '%s': %w", report.Name, err)
+		}
+		for _, list := range lists {
+			printer.PrintList(list)
+			fmt.Println()
 		}
 	}
-	sort.Slice(defenders, func(i, j int) bool {
-		return float32(defenders[i].TotalPoints) > float32(defenders[j].TotalPoints)
-	})
-	return defenders[:20]
+	return nil
 }
 
-func mostAttackingDefenders(players []models.Player) []models.Player {
-	defenders := make([]models.Player, 0)
-	for _, player := range players {
-		if player.Type.ID == models.PTDefender {
-			defenders = append(defenders, player)
-		}
+// Players loads every player once and returns the same slice afterwards.
+func (i *Insights) Players() ([]models.Player, error) {
+	if i.players != nil {
+		return i.players, nil
+	}
+	players, err := i.Store.GetPlayers(store.PlayerFilter{})
+	if err != nil {
+		return nil, err
 	}
-	sort.Slice(defenders, func(i, j int) bool {
-		return defenders[i].AttackingPoints() > defenders[j].AttackingPoints()
-	})
-	return defenders[:20]
+	i.players = players
+	return players, nil
 }
 
-func defendersWithMostCleanSheetPoints(players []models.Player) []models.Player {
-	defenders := make([]models.Player, 0)
-	for _, player := range players {
-		if player.Type.ID == models.PTDefender {
-			defenders = append(defenders, player)
-		}
+func (i *Insights) PlayerTypes() ([]models.PlayerType, error) {
+	if i.playerTypes != nil {
+		return i.playerTypes, nil
+	}
+	playerTypes, err := i.Store.GetPlayerTypes()
+	if err != nil {
+		return nil, err
 	}
-	sort.Slice(defenders, func(i, j int) bool {
-		return defenders[i].CleanSheets() > defenders[j].CleanSheets()
-	})
-	return defenders[:20]
+	i.playerTypes = playerTypes
+	return playerTypes, nil
 }
 
-// func bestValueDefenders(players []models.Player) []models.Player {
-// 	defenders := make([]models.Player, 0)
-// 	for _, player := range players {
-// 		if player.Type.ID == models.PTDefender {
-// 			defenders = append(defenders, player)
-// 		}
-// 	}
-// 	sort.Slice(defenders, func(i, j int) bool {
-// 		return defenders[i].PointsOverCost() > defenders[j].PointsOverCost()
-// 	})
-// 	return defenders[:20]
-// }
+// eachPosition calls f with every selected position and all players.
+func (i *Insights) eachPosition(f func(playerType models.PlayerType, players []models.Player)) error {
+	players, err := i.Players()
+	if err != nil {
+		return err
+	}
+	playerTypes, err := i.PlayerTypes()
+	if err != nil {
+		return err
+	}
+	for _, playerType := range playerTypes {
+		if len(i.Positions) > 0 && !containsPosition(i.Positions, playerType.ID) {
+			continue
+		}
+		f(playerType, players)
+	}
+	return nil
+}
 
-// func printPlayerCostsList(title string, players []models.Player) {
-// 	list := printer.List{
-// 		Title: title,
-// 		Items: make([]printer.ListItem, 0),
-// 	}
-// 	for _, player := range players {
-// 		list.Items = append(list.Items, printer.ListItem{
-// 			Format: "%s (%s) (%.2f)",
-// 			Values: []interface{}{
-// 				player.Name,
-// 				player.Cost,
-// 				player.AttackingForm(4),
-// 			},
-// 		})
-// 	}
-// 	printer.PrintList(list)
-// }
+// LastGameweek is the season's last gameweek, loaded once.
+func (i *Insights) LastGameweek() (int, error) {
+	if i.lastGameweek > 0 {
+		return i.lastGameweek, nil
+	}
+	gameweeks, err := i.Store.GetGameweeks()
+	if err != nil {
+		return 0, err
+	}
+	for _, gameweek := range gameweeks {
+		i.lastGameweek = max(i.lastGameweek, int(gameweek.ID))
+	}
+	return i.lastGameweek, nil
+}
 
-// func printPlayerBonusList(title string, players []models.Player) {
-// 	list := printer.List{
-// 		Title: title,
-// 		Items: make([]printer.ListItem, 0),
-// 	}
-// 	for _, player := range players {
-// 		list.Items = append(list.Items, printer.ListItem{
-// 			Format: "%s (%d)",
-// 			Values: []interface{}{
-// 				player.Name,
-// 				player.Stats.Bonus,
-// 			},
-// 		})
-// 	}
-// 	printer.PrintList(list)
-// }
+// gameweeksAhead is the range of gameweeks from the one after the current to
+// horizon gameweeks ahead, cut short at the season's last gameweek.
+func (i *Insights) gameweeksAhead(horizon int) (models.GameweekID, models.GameweekID, error) {
+	if horizon < 1 {
+		return 0, 0, fmt.Errorf("horizon must be at least 1, got %d", horizon)
+	}
+	last, err := i.LastGameweek()
+	if err != nil {
+		return 0, 0, err
+	}
+	if i.Gameweek >= last {
+		return 0, 0, fmt.Errorf("no gameweeks left after gameweek %d", i.Gameweek)
+	}
+	return models.GameweekID(i.Gameweek + 1), models.GameweekID(min(i.Gameweek+horizon, last)), nil
+}
@@ -0,0 +1,121 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/squad"
+	"errors"
+	"fmt"
+)
+
+func init() {
+	Register(Report{
+		Name:        "lineup",
+		Description: "the manager's best starting eleven and bench order for the next gameweek, when a manager is given",
+		Params:      []string{"manager"},
+		Default:     true,
+		Run: func(i *Insights) ([]printer.List, error) {
+			if i.ManagerID == 0 {
+				return nil, nil
+			}
+			// a database imported without the manager's picks shouldn't fail
+			// every default report
+			managerSquad, err := i.ManagerSquad()
+			if err != nil && !errors.Is(err, ErrNoPicks) {
+				return nil, err
+			}
+			if len(managerSquad.Players) < squad.StartingSize {
+				return []printer.List{{
+					Title: fmt.Sprintf("No lineup for manager %d, only %d picks imported for gameweek %d (run with -update to fetch them)", i.ManagerID, len(managerSquad.Players), i.Gameweek),
+				}}, nil
+			}
+			playerTypes, err := i.PlayerTypes()
+			if err != nil {
+				return nil, err
+			}
+			projections, err := i.projectionsOver(1)
+			if err != nil {
+				return nil, err
+			}
+
+			points := make(map[models.PlayerID]float32, len(projections))
+			chances := make(map[models.PlayerID]float32, len(projections))
+			nextFixtures := make(map[models.TeamID]*models.Fixture, 0)
+			overallRanks := make(map[models.PlayerID]int, len(projections))
+			typeRanks := make(map[models.PlayerID]int, len(projections))
+			typeCounts := make(map[models.PlayerTypeID]int, 0)
+			// projections are highest first, so their order is the rank
+			for n, projection := range projections {
+				player := projection.Player
+				points[player.ID] = projection.Points
+				overallRanks[player.ID] = n + 1
+				typeCounts[player.Type.ID]++
+				typeRanks[player.ID] = typeCounts[player.Type.ID]
+				if len(projection.Gameweeks) == 0 {
+					continue
+				}
+				gameweek := projection.Gameweeks[0]
+				chances[player.ID] = gameweek.Chance()
+				if len(gameweek.Fixtures) > 0 && player.Team != nil {
+					nextFixtures[player.Team.ID] = gameweek.Fixtures[0].Fixture
+				}
+			}
+
+			lineup := squad.PickLineup(managerSquad.Players, playerTypes, points).OrderBench(playerTypes, points, chances)
+			eleven := lineup.StartingEleven(nextFixtures, overallRanks, typeRanks)
+
+			starters := printer.List{
+				Title: fmt.Sprintf("Starting eleven for gameweek %d (%s, %.1f xP):", i.Gameweek+1, lineup.Formation(), lineup.Points(points, 0)),
+				Items: make([]printer.ListItem, 0),
+			}
+			for _, playerType := range playerTypes {
+				for _, starter := range eleven[playerType.Name] {
+					starters.Items = append(starters.Items, printer.ListItem{
+						Format: "%s %s (%s) %s (%.1f xP, #%s %s, #%s overall)",
+						Values: []interface{}{
+							playerType.ShortName,
+							starter.Player.Name,
+							starter.Player.Cost,
+							opponent(starter),
+							points[starter.Player.ID],
+							starter.TypeRank,
+							playerType.ShortName,
+							starter.OverallRank,
+						},
+					})
+				}
+			}
+
+			bench := printer.List{
+				Title: "Bench:",
+				Items: make([]printer.ListItem, 0),
+			}
+			for n, sub := range lineup.Bench {
+				bench.Items = append(bench.Items, printer.ListItem{
+					Format: "%d. %s %s (%s) (%.1f xP, %.1f autosub)",
+					Values: []interface{}{
+						n + 1,
+						sub.Type.ShortName,
+						sub.Name,
+						sub.Cost,
+						points[sub.ID],
+						lineup.AutosubValue(sub, playerTypes, points, chances),
+					},
+				})
+			}
+			return []printer.List{starters, bench}, nil
+		},
+	})
+}
+
+// opponent describes a starter's next fixture, e.g. "vs T03 (H)".
+func opponent(starter models.StartingPlayer) string {
+	if starter.Fixture.HomeTeam == nil {
+		return "no fixture"
+	}
+	venue := "A"
+	if starter.Player.Team != nil && starter.Fixture.HomeTeam.ID == starter.Player.Team.ID {
+		venue = "H"
+	}
+	return fmt.Sprintf("vs %s (%s)", starter.OpposingTeam.ShortName, venue)
+}
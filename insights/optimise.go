@@ -0,0 +1,93 @@
+package insights
+
+import (
+	"better-fantasy/printer"
+	"better-fantasy/squad"
+	"fmt"
+)
+
+func init() {
+	Register(Report{
+		Name:        "wildcard",
+		Description: "a squad built from scratch for projected points over the horizon, by swapping players until no swap helps",
+		Params:      []string{"horizon", "budget", "manager"},
+		Run: func(i *Insights) ([]printer.List, error) {
+			return i.optimisedSquad("Wildcard", i.Horizon)
+		},
+	})
+	Register(Report{
+		Name:        "free-hit",
+		Description: "a squad built from scratch for projected points in the next gameweek, by swapping players until no swap helps",
+		Params:      []string{"budget", "manager"},
+		Run: func(i *Insights) ([]printer.List, error) {
+			return i.optimisedSquad("Free hit", 1)
+		},
+	})
+}
+
+func (i *Insights) optimisedSquad(title string, horizon int) ([]printer.List, error) {
+	players, err := i.Players()
+	if err != nil {
+		return nil, err
+	}
+	playerTypes, err := i.PlayerTypes()
+	if err != nil {
+		return nil, err
+	}
+	projected, err := i.projectedPointsOver(horizon)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := i.gameweeksAhead(horizon)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := i.SquadBudget()
+	if err != nil {
+		return nil, err
+	}
+
+	optimiser := squad.NewOptimiser(projected, playerTypes, budget)
+	optimised, lineup, err := optimiser.Optimise(players)
+	if err != nil {
+		return nil, err
+	}
+
+	starters := printer.List{
+		Title: fmt.Sprintf("%s squad for gameweeks %d-%d, best found by player swaps, not guaranteed optimal (£%.1fm, £%.1fm left, %.1f xP starting):", title, from, to, optimised.Value(), optimised.Bank, lineup.Points(projected, 0)),
+		Items: make([]printer.ListItem, 0),
+	}
+	for _, player := range lineup.Starters {
+		starters.Items = append(starters.Items, printer.ListItem{
+			Format: "%s %s (%s) (%.1f xP)",
+			Values: []interface{}{player.Type.ShortName, player.Name, player.Cost, projected[player.ID]},
+		})
+	}
+	bench := printer.List{
+		Title: "Bench:",
+		Items: make([]printer.ListItem, 0),
+	}
+	for n, player := range lineup.Bench {
+		bench.Items = append(bench.Items, printer.ListItem{
+			Format: "%d. %s %s (%s) (%.1f xP)",
+			Values: []interface{}{n + 1, player.Type.ShortName, player.Name, player.Cost, projected[player.ID]},
+		})
+	}
+	return []printer.List{starters, bench}, nil
+}
+
+// SquadBudget is Budget if set, otherwise what the manager's squad and bank
+// are worth, or squad.DefaultBudget without a manager.
+func (i *Insights) SquadBudget() (float32, error) {
+	if i.Budget > 0 {
+		return i.Budget, nil
+	}
+	if i.ManagerID == 0 {
+		return squad.DefaultBudget, nil
+	}
+	managerSquad, err := i.ManagerSquad()
+	if err != nil {
+		return 0, err
+	}
+	return managerSquad.Value() + managerSquad.Bank, nil
+}
@@ -0,0 +1,97 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/projection"
+	"fmt"
+	"strings"
+)
+
+var projectionReport = Report{
+	Name:        "projection",
+	Description: "players of each position with the most expected points over the coming gameweeks",
+	Params:      []string{"position", "top", "horizon"},
+	Run: func(i *Insights) ([]printer.List, error) {
+		projections, err := i.Projections()
+		if err != nil {
+			return nil, err
+		}
+		from, to, err := i.gameweeksAhead(i.Horizon)
+		if err != nil {
+			return nil, err
+		}
+		lists := make([]printer.List, 0)
+		err = i.eachPosition(func(playerType models.PlayerType, players []models.Player) {
+			list := printer.List{
+				Title: capitalise(fmt.Sprintf("%s with most expected points, gameweeks %d-%d:", strings.ToLower(playerType.PluralName), from, to)),
+				Items: make([]printer.ListItem, 0),
+			}
+			for _, projection := range projections {
+				if len(list.Items) == i.Top {
+					break
+				}
+				if projection.Player.Type.ID != playerType.ID {
+					continue
+				}
+				gameweekPoints := make([]string, 0, len(projection.Gameweeks))
+				for _, gameweek := range projection.Gameweeks {
+					gameweekPoints = append(gameweekPoints, fmt.Sprintf("%.1f", gameweek.Points))
+				}
+				list.Items = append(list.Items, printer.ListItem{
+					Format: "%s (%s) (%.1f xP: %s)",
+					Values: []interface{}{
+						projection.Player.Name,
+						projection.Player.Cost,
+						projection.Points,
+						strings.Join(gameweekPoints, ", "),
+					},
+				})
+			}
+			lists = append(lists, list)
+		})
+		return lists, err
+	},
+}
+
+func init() {
+	Register(projectionReport)
+}
+
+// Projections projects every player over the Horizon gameweeks after the
+// current one, highest first.
+func (i *Insights) Projections() ([]projection.Projection, error) {
+	return i.projectionsOver(i.Horizon)
+}
+
+// projectionsOver projects every player over the given number of gameweeks
+// after the current one, up to the season's last. Each horizon is worked out
+// once and reused.
+func (i *Insights) projectionsOver(horizon int) ([]projection.Projection, error) {
+	from, to, err := i.gameweeksAhead(horizon)
+	if err != nil {
+		return nil, err
+	}
+	horizon = int(to - from + 1)
+	if projections, ok := i.projections[horizon]; ok {
+		return projections, nil
+	}
+	players, err := i.Players()
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := i.Store.GetFixtures(int(from), int(to))
+	if err != nil {
+		return nil, err
+	}
+	ratings, err := i.Strength()
+	if err != nil {
+		return nil, err
+	}
+	model := projection.NewModel(players, fixtures, ratings)
+	if i.projections == nil {
+		i.projections = make(map[int][]projection.Projection, 0)
+	}
+	i.projections[horizon] = model.ProjectAll(players, from, to)
+	return i.projections[horizon], nil
+}
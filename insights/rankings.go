@@ -0,0 +1,209 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ranking orders the players of a position by one measure.
+type Ranking struct {
+	// Title is formatted with the number of players and the position's plural
+	// name.
+	Title string
+	// Format prints a player's value, after their name and cost.
+	Format string
+	Value  func(player *models.Player) float32
+	// MinMinutes leaves out players who haven't played enough for Value to
+	// mean much, e.g. for rates.
+	MinMinutes int
+	// NeedsHistory leaves out players without a history, for values only
+	// their history has.
+	NeedsHistory bool
+}
+
+var (
+	totalPointsRanking = Ranking{
+		Title:  "Top %d %s:",
+		Format: "%.0f pts",
+		Value: func(player *models.Player) float32 {
+			return float32(player.TotalPoints)
+		},
+	}
+	attackingRanking = Ranking{
+		Title:  "Most attacking %[2]s (total points g/a):",
+		Format: "%.0f",
+		Value: func(player *models.Player) float32 {
+			return player.AttackingPoints()
+		},
+	}
+	cleanSheetRanking = Ranking{
+		Title:  "%[2]s with most clean sheets:",
+		Format: "%.0f",
+		Value: func(player *models.Player) float32 {
+			return player.CleanSheets()
+		},
+	}
+	savesRanking = Ranking{
+		Title:  "%[2]s with most saves:",
+		Format: "%.0f",
+		Value: func(player *models.Player) float32 {
+			return player.Saves()
+		},
+		NeedsHistory: true,
+	}
+	savesPer90Ranking = Ranking{
+		Title:  "%[2]s with most saves per 90:",
+		Format: "%.2f",
+		Value: func(player *models.Player) float32 {
+			return player.Per90().Saves
+		},
+		MinMinutes: models.DefaultMinMinutes,
+	}
+	goalInvolvementRanking = Ranking{
+		Title:  "%[2]s with most goal involvements:",
+		Format: "%.0f",
+		Value: func(player *models.Player) float32 {
+			return float32(player.Stats.Goals + player.Stats.Assists)
+		},
+	}
+	goalInvolvementPer90Ranking = Ranking{
+		Title:  "%[2]s with most goal involvements per 90:",
+		Format: "%.2f",
+		Value: func(player *models.Player) float32 {
+			return player.Per90().GoalInvolvements
+		},
+		MinMinutes: models.DefaultMinMinutes,
+	}
+	bonusRanking = Ranking{
+		Title:  "%[2]s with most bonus points:",
+		Format: "%.0f",
+		Value: func(player *models.Player) float32 {
+			return float32(player.Stats.Bonus)
+		},
+	}
+)
+
+// rankingsByPosition are the rankings that make sense for each position.
+var rankingsByPosition = map[models.PlayerTypeID][]Ranking{
+	models.PTGoalkeeper: {totalPointsRanking, savesRanking, savesPer90Ranking, cleanSheetRanking},
+	models.PTDefender:   {totalPointsRanking, attackingRanking, cleanSheetRanking},
+	models.PTMidfielder: {totalPointsRanking, goalInvolvementRanking, goalInvolvementPer90Ranking, bonusRanking},
+	models.PTForward:    {totalPointsRanking, goalInvolvementRanking, goalInvolvementPer90Ranking, bonusRanking},
+}
+
+// rankingsReport prints every ranking for the selected positions.
+var rankingsReport = Report{
+	Name:        "rankings",
+	Description: "top players of each position by points and the measures that suit the position, e.g. saves for goalkeepers",
+	Params:      []string{"position", "top"},
+	Default:     true,
+	Run: func(i *Insights) ([]printer.List, error) {
+		lists := make([]printer.List, 0)
+		err := i.eachPosition(func(playerType models.PlayerType, players []models.Player) {
+			for _, ranking := range rankingsByPosition[playerType.ID] {
+				lists = append(lists, ranking.list(players, playerType, i.Top))
+			}
+		})
+		return lists, err
+	},
+}
+
+func init() {
+	Register(rankingsReport)
+}
+
+func (r Ranking) list(players []models.Player, playerType models.PlayerType, top int) printer.List {
+	withoutHistory := 0
+	if r.NeedsHistory {
+		withHistory := make([]models.Player, 0, len(players))
+		for _, player := range players {
+			if len(player.History) > 0 {
+				withHistory = append(withHistory, player)
+			} else if player.Type.ID == playerType.ID {
+				withoutHistory++
+			}
+		}
+		players = withHistory
+	}
+	ranked := rankPlayers(players, playerType.ID, top, r.MinMinutes, r.Value)
+	title := capitalise(fmt.Sprintf(r.Title, len(ranked), strings.ToLower(playerType.PluralName)))
+	if withoutHistory > 0 {
+		title = fmt.Sprintf("%s (%d without a history left out, run with -update to fetch them)", strings.TrimSuffix(title, ":"), withoutHistory) + ":"
+	}
+	list := printer.List{
+		Title: title,
+		Items: make([]printer.ListItem, 0),
+	}
+	for _, player := range ranked {
+		list.Items = append(list.Items, printer.ListItem{
+			Format: "%s (%s) (" + r.Format + ")",
+			Values: []interface{}{
+				player.Name,
+				player.Cost,
+				r.Value(&player),
+			},
+		})
+	}
+	return list
+}
+
+// rankPlayers returns the top players of a position by value, or all of them
+// if there are fewer than top.
+func rankPlayers(players []models.Player, position models.PlayerTypeID, top int, minMinutes int, value func(player *models.Player) float32) []models.Player {
+	ranked := make([]models.Player, 0)
+	for _, player := range players {
+		if player.Type.ID == position && player.HasMinutes(minMinutes) {
+			ranked = append(ranked, player)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return value(&ranked[i]) > value(&ranked[j])
+	})
+	if top > 0 && len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	return ranked
+}
+
+// ParsePositions maps comma separated short names, e.g. "GKP,DEF", to their
+// player types. An empty string selects every position.
+func ParsePositions(playerTypes []models.PlayerType, positions string) ([]models.PlayerTypeID, error) {
+	selected := make([]models.PlayerTypeID, 0)
+	if positions == "" {
+		return selected, nil
+	}
+	for _, position := range strings.Split(positions, ",") {
+		position = strings.TrimSpace(position)
+		found := false
+		for _, playerType := range playerTypes {
+			if strings.EqualFold(playerType.ShortName, position) || strings.EqualFold(playerType.Name, position) {
+				selected = append(selected, playerType.ID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown position '%s'", position)
+		}
+	}
+	return selected, nil
+}
+
+func capitalise(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func containsPosition(positions []models.PlayerTypeID, position models.PlayerTypeID) bool {
+	for _, p := range positions {
+		if p == position {
+			return true
+		}
+	}
+	return false
+}
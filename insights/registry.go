@@ -0,0 +1,44 @@
+package insights
+
+import (
+	"better-fantasy/printer"
+	"fmt"
+)
+
+// Report is a named insight which produces lists to print.
+type Report struct {
+	Name        string
+	Description string
+	// Params names the Insights options the report reads, e.g. "position"
+	// and "top".
+	Params []string
+	// Default reports run when no reports are chosen.
+	Default bool
+	Run     func(i *Insights) ([]printer.List, error)
+}
+
+var registry = make([]Report, 0)
+
+// Register adds a report. Names must be unique.
+func Register(report Report) {
+	if _, ok := ReportByName(report.Name); ok {
+		panic(fmt.Sprintf("report '%s' registered twice", report.Name))
+	}
+	registry = append(registry, report)
+}
+
+// Reports returns every registered report in the order registered.
+func Reports() []Report {
+	reports := make([]Report, len(registry))
+	copy(reports, registry)
+	return reports
+}
+
+func ReportByName(name string) (Report, bool) {
+	for _, report := range registry {
+		if report.Name == name {
+			return report, true
+		}
+	}
+	return Report{}, false
+}
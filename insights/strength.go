@@ -0,0 +1,63 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/strength"
+	"sort"
+)
+
+func init() {
+	Register(Report{
+		Name:        "strength",
+		Description: "every team's attack and defence ratings at home and away, best attack first",
+		Run: func(i *Insights) ([]printer.List, error) {
+			ratings, err := i.Strength()
+			if err != nil {
+				return nil, err
+			}
+			teams, err := i.Store.GetTeams()
+			if err != nil {
+				return nil, err
+			}
+			attack := func(rating strength.TeamRating) float64 {
+				return ratings.HomeGoals*rating.Home.Attack + ratings.AwayGoals*rating.Away.Attack
+			}
+			sorted := make([]*models.Team, len(teams))
+			copy(sorted, teams)
+			sort.SliceStable(sorted, func(a, b int) bool {
+				return attack(ratings.Team(sorted[a].ID)) > attack(ratings.Team(sorted[b].ID))
+			})
+
+			list := printer.List{
+				Title: "Team strength (attack above 1 scores more than average, defence below 1 concedes less):",
+				Items: make([]printer.ListItem, 0),
+			}
+			for _, team := range sorted {
+				rating := ratings.Team(team.ID)
+				list.Items = append(list.Items, printer.ListItem{
+					Format: "%s: home attack %.2f, defence %.2f; away attack %.2f, defence %.2f (%d matches)",
+					Values: []interface{}{team.ShortName, rating.Home.Attack, rating.Home.Defence, rating.Away.Attack, rating.Away.Defence, rating.Matches},
+				})
+			}
+			return []printer.List{list}, nil
+		},
+	})
+}
+
+// Strength rates every team from the results so far, once.
+func (i *Insights) Strength() (*strength.Ratings, error) {
+	if i.strength != nil {
+		return i.strength, nil
+	}
+	players, err := i.Players()
+	if err != nil {
+		return nil, err
+	}
+	fixtures, err := i.Store.GetFixtures(1, i.Gameweek)
+	if err != nil {
+		return nil, err
+	}
+	i.strength = strength.Rate(players, fixtures)
+	return i.strength, nil
+}
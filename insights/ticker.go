@@ -0,0 +1,104 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/ticker"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(Report{
+		Name:        "ticker",
+		Description: "every team's opponents and difficulty over the horizon, easiest first over the window, with each team's best run",
+		Params:      []string{"horizon", "window", "official-difficulty"},
+		Run: func(i *Insights) ([]printer.List, error) {
+			teams, err := i.Store.GetTeams()
+			if err != nil {
+				return nil, err
+			}
+			from, to, err := i.gameweeksAhead(i.Horizon)
+			if err != nil {
+				return nil, err
+			}
+			gameweeks := int(to - from + 1)
+			fixtures, err := i.Store.GetFixtures(int(from), int(to))
+			if err != nil {
+				return nil, err
+			}
+			difficulty := ticker.OfficialDifficulty
+			if !i.OfficialDifficulty {
+				ratings, err := i.Strength()
+				if err != nil {
+					return nil, err
+				}
+				difficulty = func(fixture *models.Fixture, teamID models.TeamID) float32 {
+					return float32(ratings.Difficulty(fixture, teamID))
+				}
+			}
+			rows := ticker.Build(teams, fixtures, from, to, i.Window, difficulty)
+
+			// every column is as wide as its widest cell
+			cells := make([][]string, len(rows))
+			widths := make([]int, gameweeks)
+			for n, row := range rows {
+				cells[n] = make([]string, len(row.Cells))
+				for k, cell := range row.Cells {
+					cells[n][k] = tickerCell(cell)
+					if len(cells[n][k]) > widths[k] {
+						widths[k] = len(cells[n][k])
+					}
+				}
+			}
+
+			header := make([]string, 0, gameweeks)
+			for k := range widths {
+				label := fmt.Sprintf("GW%d", int(from)+k)
+				if len(label) > widths[k] {
+					widths[k] = len(label)
+				}
+				header = append(header, fmt.Sprintf("%-*s", widths[k], label))
+			}
+			list := printer.List{
+				Title: fmt.Sprintf("Fixture ticker for gameweeks %d-%d, easiest first over %d gameweeks (best run in brackets):", from, to, ticker.Window(i.Window, gameweeks)),
+				Items: []printer.ListItem{{
+					Format: "%-4s %-4s  %s",
+					Values: []interface{}{"", "avg", strings.Join(header, " ")},
+				}},
+			}
+			for n, row := range rows {
+				line := make([]string, 0, len(row.Cells))
+				for k := range row.Cells {
+					line = append(line, fmt.Sprintf("%-*s", widths[k], cells[n][k]))
+				}
+				list.Items = append(list.Items, printer.ListItem{
+					Format: "%-4s %.2f  %s  best GW%d-%d (%.2f)",
+					Values: []interface{}{row.Team.ShortName, row.Difficulty, strings.Join(line, " "), row.BestRun.From, row.BestRun.To, row.BestRun.Difficulty},
+				})
+			}
+			return []printer.List{list}, nil
+		},
+	})
+}
+
+// tickerCell shows a gameweek's opponents with their venue and difficulty,
+// e.g. "T03(H)2.4", bracketed when it's part of the best run.
+func tickerCell(cell ticker.Cell) string {
+	opponents := make([]string, 0, len(cell.Fixtures))
+	for _, opponent := range cell.Fixtures {
+		venue := "A"
+		if opponent.Home {
+			venue = "H"
+		}
+		opponents = append(opponents, fmt.Sprintf("%s(%s)%.1f", opponent.Team.ShortName, venue, opponent.Difficulty))
+	}
+	text := "-"
+	if len(opponents) > 0 {
+		text = strings.Join(opponents, "+")
+	}
+	if cell.InBestRun {
+		return "[" + text + "]"
+	}
+	return " " + text + " "
+}
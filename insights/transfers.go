@@ -0,0 +1,122 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/squad"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var transfersReport = Report{
+	Name:        "transfers",
+	Description: "the best 0, 1 and 2 transfers for the manager's squad by projected points, including hits",
+	Params:      []string{"manager", "horizon", "free-transfers"},
+	Run: func(i *Insights) ([]printer.List, error) {
+		managerSquad, err := i.ManagerSquad()
+		if err != nil {
+			return nil, err
+		}
+		players, err := i.Players()
+		if err != nil {
+			return nil, err
+		}
+		projected, err := i.ProjectedPoints()
+		if err != nil {
+			return nil, err
+		}
+		playerTypes, err := i.PlayerTypes()
+		if err != nil {
+			return nil, err
+		}
+		if err := managerSquad.Validate(playerTypes); err != nil {
+			return nil, fmt.Errorf("squad isn't valid: %w", err)
+		}
+
+		from, to, err := i.gameweeksAhead(i.Horizon)
+		if err != nil {
+			return nil, err
+		}
+
+		var squadPoints float32
+		for _, player := range managerSquad.Players {
+			squadPoints += projected[player.ID]
+		}
+		planner := squad.NewPlanner(projected, i.FreeTransfers)
+		plans := planner.Plan(managerSquad, players)
+
+		list := printer.List{
+			Title: fmt.Sprintf("Transfers for gameweeks %d-%d (squad %.1f xP, £%.1fm in the bank, %d free):", from, to, squadPoints, managerSquad.Bank, i.FreeTransfers),
+			Items: make([]printer.ListItem, 0),
+		}
+		for _, plan := range plans {
+			moves := make([]string, 0, len(plan.Transfers))
+			for _, transfer := range plan.Transfers {
+				moves = append(moves, fmt.Sprintf("%s (%s) -> %s (%s)", transfer.Out.Name, transfer.Out.Cost, transfer.In.Name, transfer.In.Cost))
+			}
+			description := "Roll the transfer"
+			if len(moves) > 0 {
+				description = strings.Join(moves, ", ")
+			}
+			list.Items = append(list.Items, printer.ListItem{
+				Format: "%s (%+.1f xP, -%d hit, £%.1fm left)",
+				Values: []interface{}{
+					description,
+					plan.Gain,
+					plan.Hits,
+					plan.Bank,
+				},
+			})
+		}
+		return []printer.List{list}, nil
+	},
+}
+
+func init() {
+	Register(transfersReport)
+}
+
+// ErrNoPicks is returned by ManagerSquad when the manager's picks for the
+// current gameweek weren't imported.
+var ErrNoPicks = errors.New("no picks imported")
+
+// ManagerSquad is ManagerID's squad and bank for the current gameweek.
+func (i *Insights) ManagerSquad() (squad.Squad, error) {
+	if i.ManagerID == 0 {
+		return squad.Squad{}, errors.New("needs a manager")
+	}
+	picks, err := i.Store.GetManagerPicks(i.ManagerID, i.Gameweek)
+	if err != nil {
+		return squad.Squad{}, err
+	}
+	if len(picks) == 0 {
+		return squad.Squad{}, fmt.Errorf("%w for manager %d in gameweek %d", ErrNoPicks, i.ManagerID, i.Gameweek)
+	}
+	managerGameweek, err := i.Store.GetManagerGameweek(i.ManagerID, i.Gameweek)
+	if err != nil {
+		return squad.Squad{}, err
+	}
+	players, err := i.Players()
+	if err != nil {
+		return squad.Squad{}, err
+	}
+	return squad.FromPicks(picks, players, managerGameweek.Bank)
+}
+
+// ProjectedPoints is each player's projected points over the horizon.
+func (i *Insights) ProjectedPoints() (map[models.PlayerID]float32, error) {
+	return i.projectedPointsOver(i.Horizon)
+}
+
+func (i *Insights) projectedPointsOver(horizon int) (map[models.PlayerID]float32, error) {
+	projections, err := i.projectionsOver(horizon)
+	if err != nil {
+		return nil, err
+	}
+	projected := make(map[models.PlayerID]float32, len(projections))
+	for _, projection := range projections {
+		projected[projection.Player.ID] = projection.Points
+	}
+	return projected, nil
+}
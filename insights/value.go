@@ -0,0 +1,45 @@
+package insights
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/printer"
+)
+
+var (
+	pointsValueRanking = Ranking{
+		Title:  "Best value %[2]s (points per £m):",
+		Format: "%.2f",
+		Value: func(player *models.Player) float32 {
+			return player.PointsOverCost()
+		},
+	}
+	formValueRanking = Ranking{
+		Title:  "%[2]s in the best form for their cost (form per £m):",
+		Format: "%.2f",
+		Value: func(player *models.Player) float32 {
+			return player.FormOverCost()
+		},
+	}
+)
+
+func init() {
+	Register(positionReport("value", "players of each position with the most points per £m", pointsValueRanking))
+	Register(positionReport("form-value", "players of each position with the best form per £m", formValueRanking))
+	Register(positionReport("bonus", "players of each position with the most bonus points", bonusRanking))
+}
+
+// positionReport is a report of one ranking for each selected position.
+func positionReport(name string, description string, ranking Ranking) Report {
+	return Report{
+		Name:        name,
+		Description: description,
+		Params:      []string{"position", "top"},
+		Run: func(i *Insights) ([]printer.List, error) {
+			lists := make([]printer.List, 0)
+			err := i.eachPosition(func(playerType models.PlayerType, players []models.Player) {
+				lists = append(lists, ranking.list(players, playerType, i.Top))
+			})
+			return lists, err
+		},
+	}
+}
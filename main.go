@@ -2,42 +2,393 @@ package main
 
 import (
 	"better-fantasy/api"
+	"better-fantasy/export"
 	"better-fantasy/insights"
+	"better-fantasy/models"
+	"better-fantasy/printer"
+	"better-fantasy/projection"
+	"better-fantasy/squad"
 	"better-fantasy/store"
+	"better-fantasy/ticker"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 )
 
 func main() {
 	managerID := flag.Int("manager", 0, "manager id")
 	dump := flag.Bool("dump", false, "for saving data to file")
+	force := flag.Bool("force", false, "overwrite an existing export when dumping")
+	loadDir := flag.String("load", "", "rebuild the database from an export directory, e.g. exports/gw_14")
+	baseURL := flag.String("api", api.DefaultBaseURL, "base url of the fpl api")
+	recordDir := flag.String("record", "", "directory to save every api response to")
+	replayDir := flag.String("replay", "", "directory of recorded api responses to import from instead of the api")
+	concurrency := flag.Int("concurrency", api.DefaultConcurrency, "number of player histories to request at once")
+	rateLimit := flag.Float64("rate", 10, "maximum api requests per second (0 for no limit)")
+	trendPlayerID := flag.Int("trend", 0, "print a player's cost and form for each imported gameweek")
+	trendFrom := flag.Int("from", 1, "first gameweek of -trend")
+	trendTo := flag.Int("to", 38, "last gameweek of -trend")
+	showMigrations := flag.Bool("migrations", false, "list applied and pending database migrations")
+	update := flag.Bool("update", false, "refresh an imported gameweek, only refetching players that have changed")
+	exportFormat := flag.String("export", "", "write players, fixtures, histories, teams and picks as csv, json or ndjson")
+	exportDir := flag.String("export-dir", "", "directory -export writes to (defaults to data in the exports directory)")
+	exportGameweek := flag.Int("gameweek", 0, "only -export this gameweek (0 for every gameweek)")
+	configFile := flag.String("config", defaultConfigFile(), "json config file giving db, name and exports_dir")
+	dbPath := flag.String("db", "", "database file, or :memory: for an in-memory database (env "+store.EnvPath+")")
+	dbName := flag.String("name", "", "use a named database, data/<name>.sqlite, with its own exports/<name> (env "+store.EnvName+")")
+	exportsDir := flag.String("exports", "", "directory -dump writes gameweek exports to (env "+store.EnvExportsDir+")")
+	pointsPlayerID := flag.Int("points", 0, "break down a player's points per fixture and reconcile them with the api")
+	season := flag.String("season", models.DefaultScoringRules.Season, "season whose scoring rules -points uses")
+	positions := flag.String("position", "", "only rank these positions, e.g. GKP,DEF (all when empty)")
+	top := flag.Int("top", insights.DefaultTop, "number of players in each ranking")
+	listReports := flag.Bool("reports", false, "list the available insight reports")
+	reportNames := flag.String("report", "", "comma separated insight reports to run instead of the defaults")
+	horizon := flag.Int("horizon", projection.DefaultHorizon, "number of gameweeks ahead to project")
+	window := flag.Int("window", ticker.DefaultWindow, "gameweeks the ticker report sorts teams by and looks for runs over")
+	officialDifficulty := flag.Bool("official-difficulty", false, "rate the ticker report's fixtures with the official difficulty instead of team strength")
+	freeTransfers := flag.Int("free-transfers", squad.DefaultFreeTransfers, "free transfers the manager has for the transfers report")
+	budget := flag.Float64("budget", 0, "budget in £m for the wildcard and free-hit reports (0 for the manager's squad value and bank, or 100)")
+	captainMode := flag.String("captain-mode", string(squad.SafeCaptain), "safe or differential captain picks")
+	chips := flag.String("chips", "wildcard,free-hit,bench-boost,triple-captain", "comma separated chips the manager has left, for the chips report")
 	flag.Parse()
+	if *top < 1 {
+		panic(fmt.Sprintf("-top must be at least 1, got %d", *top))
+	}
+	if *horizon < 1 {
+		panic(fmt.Sprintf("-horizon must be at least 1, got %d", *horizon))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	config, err := store.ReadConfig(*configFile)
+	if err != nil {
+		panic(err)
+	}
+	config = config.Merge(store.ConfigFromEnv()).Merge(store.Config{
+		Path:       *dbPath,
+		Name:       *dbName,
+		ExportsDir: *exportsDir,
+	})
+
+	if *listReports {
+		printReports()
+		return
+	}
+
+	if *showMigrations {
+		printMigrations(config)
+		return
+	}
+
+	dataStore, err := store.NewStore(config)
+	if err != nil {
+		panic(err)
+	}
+	defer dataStore.Close()
+
+	if *trendPlayerID > 0 {
+		snapshots, err := dataStore.GetPlayerSnapshots(models.PlayerID(*trendPlayerID), *trendFrom, *trendTo)
+		if err != nil {
+			panic(err)
+		}
+		trendList := printer.List{
+			Title: fmt.Sprintf("Player %d, gameweeks %d-%d:", *trendPlayerID, *trendFrom, *trendTo),
+			Items: make([]printer.ListItem, 0),
+		}
+		for _, snapshot := range snapshots {
+			trendList.Items = append(trendList.Items, printer.ListItem{
+				Format: "GW%d: %s, form %.1f, %d pts, %.1f%% owned",
+				Values: []interface{}{
+					snapshot.GameweekID,
+					snapshot.Cost,
+					snapshot.Form,
+					snapshot.TotalPoints,
+					snapshot.PickedPercentage,
+				},
+			})
+		}
+		printer.PrintList(trendList)
+		return
+	}
+
+	if *pointsPlayerID > 0 {
+		rules, ok := models.ScoringRulesBySeason[*season]
+		if !ok {
+			panic(fmt.Sprintf("no scoring rules for season '%s'", *season))
+		}
+		player, err := dataStore.GetPlayer(models.PlayerID(*pointsPlayerID))
+		if err != nil {
+			panic(err)
+		}
+		printPoints(player, rules)
+		return
+	}
 
-	store := store.NewStore()
+	if *exportFormat != "" {
+		format, err := export.ParseFormat(*exportFormat)
+		if err != nil {
+			panic(err)
+		}
+		if *exportDir == "" {
+			*exportDir = filepath.Join(config.ExportsPath(), "data")
+		}
+		files, err := export.Export(&dataStore, export.Options{
+			Dir:      *exportDir,
+			Format:   format,
+			Gameweek: *exportGameweek,
+		})
+		if err != nil {
+			panic(err)
+		}
+		for _, file := range files {
+			fmt.Printf("exported '%s'\n", file)
+		}
+		return
+	}
+
+	if *loadDir != "" {
+		if err := dataStore.ImportDump(*loadDir); err != nil {
+			panic(err)
+		}
+		if err := dataStore.MarkImported(dataStore.CurrentGameweek()); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Loaded '%s'\n\n", *loadDir)
+	}
 
-	hasImported, err := store.HasImported()
+	hasImported, err := dataStore.HasImported()
 	if err != nil {
 		panic(err)
 	}
 
-	if !hasImported {
-		fmt.Println("This may take several minutes...")
-		data, err := api.FetchData(api.FetchOptions{
-			ManagerID: *managerID,
+	limiter := api.NewRateLimiter(*rateLimit, *concurrency)
+
+	var client *api.Client
+	switch {
+	case *replayDir != "":
+		client = api.NewReplayClient(*replayDir)
+	case *recordDir != "":
+		client = api.NewRecordingClient(*baseURL, limiter, *recordDir)
+	default:
+		client = api.NewClient(*baseURL, limiter)
+	}
+
+	// recording and replaying always import unless updating, otherwise there
+	// would be nothing to record or replay
+	if !hasImported || (!*update && (*recordDir != "" || *replayDir != "")) {
+		if *replayDir == "" {
+			fmt.Println("This may take several minutes...")
+		}
+		data, err := api.FetchData(ctx, api.FetchOptions{
+			ManagerID:   *managerID,
+			Client:      client,
+			Concurrency: *concurrency,
 		})
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("Import cancelled, nothing was stored")
+			os.Exit(1)
+		}
 		if err != nil {
 			panic(err)
 		}
 
-		err = store.StoreData(data, *dump)
+		err = dataStore.StoreData(data, *dump)
 		if err != nil {
 			panic(err)
 		}
+	} else if *update {
+		storedPlayers, err := dataStore.GetPlayers(store.PlayerFilter{})
+		if err != nil {
+			panic(err)
+		}
+		known := make(map[models.PlayerID]models.Player, len(storedPlayers))
+		for _, player := range storedPlayers {
+			known[player.ID] = player
+		}
+
+		data, err := api.FetchData(ctx, api.FetchOptions{
+			ManagerID:   *managerID,
+			Client:      client,
+			Concurrency: *concurrency,
+			Known:       known,
+		})
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("Update cancelled, nothing was stored")
+			os.Exit(1)
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		summary, err := dataStore.UpdateData(data)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("Updated %d players (%d histories refetched) and %d fixtures\n\n", summary.Players, summary.Histories, summary.Fixtures)
+	}
+
+	if *dump {
+		err := dataStore.Dump(*force)
+		if errors.Is(err, store.ErrExportExists) {
+			fmt.Printf("Not dumping, %s (use -force to overwrite)\n\n", err)
+		} else if err != nil {
+			panic(err)
+		}
+	}
+
+	playerTypes, err := dataStore.GetPlayerTypes()
+	if err != nil {
+		panic(err)
+	}
+	selectedPositions, err := insights.ParsePositions(playerTypes, *positions)
+	if err != nil {
+		panic(err)
 	}
 
-	insights := insights.NewInsights(&store)
-	err = insights.Analyse()
+	insights := insights.NewInsights(&dataStore)
+	insights.Positions = selectedPositions
+	insights.Top = *top
+	insights.Horizon = *horizon
+	insights.Window = *window
+	insights.OfficialDifficulty = *officialDifficulty
+	insights.ManagerID = *managerID
+	insights.FreeTransfers = *freeTransfers
+	insights.Budget = float32(*budget)
+	insights.CaptainMode, err = squad.ParseCaptainMode(*captainMode)
+	if err != nil {
+		panic(err)
+	}
+	insights.Chips, err = squad.ParseChips(*chips)
 	if err != nil {
 		panic(err)
 	}
+	if *reportNames != "" {
+		err = insights.Run(strings.Split(*reportNames, ","))
+	} else {
+		err = insights.Analyse()
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+func printPoints(player models.Player, rules models.ScoringRules) {
+	reconciliation := player.Reconcile(rules)
+	breakdown := reconciliation.Breakdown
+
+	pointsList := printer.List{
+		Title: fmt.Sprintf("%s, %s scoring:", player.Name, rules.Season),
+		Items: make([]printer.ListItem, 0),
+	}
+	categories := []struct {
+		name   string
+		points int
+	}{
+		{"Appearances", breakdown.Appearance},
+		{"Goals", breakdown.Goals},
+		{"Assists", breakdown.Assists},
+		{"Clean sheets", breakdown.CleanSheet},
+		{"Goals conceded", breakdown.GoalsConceded},
+		{"Saves", breakdown.Saves},
+		{"Defensive contributions", breakdown.DefensiveContribution},
+		{"Penalties saved", breakdown.PenaltiesSaved},
+		{"Penalties missed", breakdown.PenaltiesMissed},
+		{"Yellow cards", breakdown.YellowCards},
+		{"Red cards", breakdown.RedCards},
+		{"Own goals", breakdown.OwnGoals},
+		{"Bonus", breakdown.Bonus},
+	}
+	for _, category := range categories {
+		pointsList.Items = append(pointsList.Items, printer.ListItem{
+			Format: "%s: %d",
+			Values: []interface{}{category.name, category.points},
+		})
+	}
+	pointsList.Items = append(pointsList.Items, printer.ListItem{
+		Format: "Total: %d calculated, %d reported by the api",
+		Values: []interface{}{reconciliation.Calculated, reconciliation.Reported},
+	})
+	printer.PrintList(pointsList)
+
+	mismatches := reconciliation.Mismatches()
+	if len(mismatches) == 0 {
+		return
+	}
+	mismatchList := printer.List{
+		Title: "Fixtures that don't reconcile:",
+		Items: make([]printer.ListItem, 0),
+	}
+	for _, fixture := range mismatches {
+		mismatchList.Items = append(mismatchList.Items, printer.ListItem{
+			Format: "Fixture %d: %d calculated, %d reported (%+d)",
+			Values: []interface{}{fixture.FixtureID, fixture.Calculated, fixture.Reported, fixture.Difference()},
+		})
+	}
+	printer.PrintList(mismatchList)
+}
+
+func printReports() {
+	reportList := printer.List{
+		Title: "Reports:",
+		Items: make([]printer.ListItem, 0),
+	}
+	for _, report := range insights.Reports() {
+		defaultReport := ""
+		if report.Default {
+			defaultReport = " (default)"
+		}
+		reportList.Items = append(reportList.Items, printer.ListItem{
+			Format: "%s%s: %s [%s]",
+			Values: []interface{}{
+				report.Name,
+				defaultReport,
+				report.Description,
+				strings.Join(report.Params, ", "),
+			},
+		})
+	}
+	printer.PrintList(reportList)
+}
+
+// defaultConfigFile is FPL_CONFIG if set, otherwise config.json in the working
+// directory.
+func defaultConfigFile() string {
+	if file := os.Getenv("FPL_CONFIG"); file != "" {
+		return file
+	}
+	return "./config.json"
+}
+
+func printMigrations(config store.Config) {
+	dataStore := store.DataStore{Config: config}
+	defer dataStore.Close()
+	states, err := dataStore.MigrationStatus()
+	if err != nil {
+		panic(err)
+	}
+	migrationList := printer.List{
+		Title: "Migrations:",
+		Items: make([]printer.ListItem, 0),
+	}
+	for _, state := range states {
+		status := "pending"
+		if state.Applied {
+			status = "applied " + state.AppliedAt
+		}
+		migrationList.Items = append(migrationList.Items, printer.ListItem{
+			Format: "%d %s (%s)",
+			Values: []interface{}{
+				state.Version,
+				state.Name,
+				status,
+			},
+		})
+	}
+	printer.PrintList(migrationList)
 }
@@ -0,0 +1,152 @@
+package models
+
+import "math"
+
+// DefaultFormHalfLife is the number of gameweeks after which a fixture counts
+// half as much towards weighted form.
+const DefaultFormHalfLife = 3
+
+// Metric measures one thing a player did in a fixture.
+type Metric func(fixture PlayerFixture, position PlayerTypeID) float32
+
+// AttackingMetric is the points from goals and assists.
+func AttackingMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	return float32(DefaultScoringRules.Score(fixture, position).Attacking())
+}
+
+// DefendingMetric is the points from clean sheets, goals conceded, saves and
+// defensive contributions.
+func DefendingMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	points := DefaultScoringRules.Score(fixture, position)
+	return float32(points.CleanSheet + points.GoalsConceded + points.Saves + points.DefensiveContribution)
+}
+
+func CleanSheetMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	if fixture.CleanSheet {
+		return 1
+	}
+	return 0
+}
+
+func GoalsConcededMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	return float32(fixture.GoalsConceded)
+}
+
+func SavesMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	return float32(fixture.Saves)
+}
+
+func MinutesMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	return float32(fixture.Minutes)
+}
+
+func BonusMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	return float32(fixture.Bonus)
+}
+
+// PointsMetric is the total points the api reported for the fixture.
+func PointsMetric(fixture PlayerFixture, position PlayerTypeID) float32 {
+	return float32(fixture.Points)
+}
+
+// RecentGameweeks returns the player's fixtures grouped by gameweek for the
+// last weeks gameweeks their team played in, most recent first. A double
+// gameweek is one group with both fixtures.
+func (p *Player) RecentGameweeks(weeks int) [][]PlayerFixture {
+	history := p.SortedHistory()
+	gameweeks := make([][]PlayerFixture, 0)
+	for i := len(history) - 1; i >= 0; i-- {
+		fixture := history[i]
+		last := len(gameweeks) - 1
+		if last >= 0 && gameweeks[last][0].GameweekID == fixture.GameweekID {
+			gameweeks[last] = append(gameweeks[last], fixture)
+			continue
+		}
+		if len(gameweeks) == weeks {
+			break
+		}
+		gameweeks = append(gameweeks, []PlayerFixture{fixture})
+	}
+	return gameweeks
+}
+
+// RollingForm averages a metric per gameweek over the last weeks gameweeks the
+// player's team played in. Players with fewer gameweeks are averaged over the
+// ones they have.
+func (p *Player) RollingForm(weeks int, metric Metric) float32 {
+	gameweeks := p.RecentGameweeks(weeks)
+	if len(gameweeks) == 0 {
+		return 0
+	}
+	var total float32
+	for _, fixtures := range gameweeks {
+		for _, fixture := range fixtures {
+			total += metric(fixture, p.Type.ID)
+		}
+	}
+	return total / float32(len(gameweeks))
+}
+
+// WeightedForm is an exponentially weighted average of a metric per gameweek
+// over the player's whole history, where a gameweek's weight halves every
+// halfLife gameweeks back.
+func (p *Player) WeightedForm(halfLife float64, metric Metric) float32 {
+	if halfLife <= 0 {
+		return 0
+	}
+	gameweeks := p.RecentGameweeks(len(p.History))
+	var total, totalWeight float64
+	for age, fixtures := range gameweeks {
+		weight := math.Pow(0.5, float64(age)/halfLife)
+		for _, fixture := range fixtures {
+			total += weight * float64(metric(fixture, p.Type.ID))
+		}
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return float32(total / totalWeight)
+}
+
+// goals & assists
+func (p *Player) AttackingForm(weeks int) float32 {
+	return p.RollingForm(weeks, AttackingMetric)
+}
+
+// clean sheets, goals conceded & saves
+func (p *Player) DefendingForm(weeks int) float32 {
+	return p.RollingForm(weeks, DefendingMetric)
+}
+
+func (p *Player) MinutesForm(weeks int) float32 {
+	return p.RollingForm(weeks, MinutesMetric)
+}
+
+func (p *Player) BonusForm(weeks int) float32 {
+	return p.RollingForm(weeks, BonusMetric)
+}
+
+func (p *Player) PointsForm(weeks int) float32 {
+	return p.RollingForm(weeks, PointsMetric)
+}
+
+func (p *Player) WeightedAttackingForm() float32 {
+	return p.WeightedForm(DefaultFormHalfLife, AttackingMetric)
+}
+
+func (p *Player) WeightedDefendingForm() float32 {
+	return p.WeightedForm(DefaultFormHalfLife, DefendingMetric)
+}
+
+func (p *Player) WeightedMinutesForm() float32 {
+	return p.WeightedForm(DefaultFormHalfLife, MinutesMetric)
+}
+
+func (p *Player) WeightedBonusForm() float32 {
+	return p.WeightedForm(DefaultFormHalfLife, BonusMetric)
+}
+
+func (p *Player) WeightedPointsForm() float32 {
+	return p.WeightedForm(DefaultFormHalfLife, PointsMetric)
+}
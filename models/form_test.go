@@ -0,0 +1,77 @@
+package models
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// formPlayer has a blank in gameweek 3 and a double in gameweek 5, whose
+// fixtures come latest first like the gameweeks.
+func formPlayer() *Player {
+	fixtures := []PlayerFixture{
+		{FixtureID: 1, GameweekID: 1, Points: 2},
+		{FixtureID: 2, GameweekID: 2, Points: 6},
+		{FixtureID: 4, GameweekID: 4, Points: 8},
+		{FixtureID: 6, GameweekID: 5, Points: 5},
+		{FixtureID: 5, GameweekID: 5, Points: 1},
+	}
+	player := &Player{
+		Type:    PlayerType{ID: PTMidfielder},
+		History: make(map[FixtureID]PlayerFixture, len(fixtures)),
+	}
+	for _, fixture := range fixtures {
+		player.History[fixture.FixtureID] = fixture
+	}
+	return player
+}
+
+func TestRecentGameweeks(t *testing.T) {
+	tests := []struct {
+		name  string
+		weeks int
+		want  [][]FixtureID
+	}{
+		{name: "double gameweek is one group", weeks: 1, want: [][]FixtureID{{6, 5}}},
+		{name: "blank gameweek is skipped", weeks: 3, want: [][]FixtureID{{6, 5}, {4}, {2}}},
+		{name: "window longer than the history", weeks: 10, want: [][]FixtureID{{6, 5}, {4}, {2}, {1}}},
+		{name: "no weeks", weeks: 0, want: [][]FixtureID{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := make([][]FixtureID, 0)
+			for _, fixtures := range formPlayer().RecentGameweeks(test.weeks) {
+				ids := make([]FixtureID, 0, len(fixtures))
+				for _, fixture := range fixtures {
+					ids = append(ids, fixture.FixtureID)
+				}
+				got = append(got, ids)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("RecentGameweeks(%d) = %v, want %v", test.weeks, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRollingForm(t *testing.T) {
+	tests := []struct {
+		name   string
+		player *Player
+		weeks  int
+		want   float32
+	}{
+		{name: "double gameweek adds both fixtures", player: formPlayer(), weeks: 1, want: 6},
+		{name: "blank gameweek isn't averaged over", player: formPlayer(), weeks: 3, want: 20.0 / 3},
+		{name: "window longer than the history", player: formPlayer(), weeks: 10, want: 5.5},
+		{name: "no history", player: &Player{}, weeks: 3, want: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.player.RollingForm(test.weeks, PointsMetric)
+			if math.Abs(float64(got-test.want)) > 1e-5 {
+				t.Errorf("RollingForm(%d) = %v, want %v", test.weeks, got, test.want)
+			}
+		})
+	}
+}
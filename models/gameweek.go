@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type GameweekID int
 
 type Gameweek struct {
@@ -15,13 +17,48 @@ type Gameweek struct {
 type FixtureID int
 
 type Fixture struct {
-	ID                 FixtureID
+	ID FixtureID
+	// Gameweek is nil until the fixture is scheduled, e.g. after it's
+	// postponed.
 	Gameweek           *Gameweek
 	HomeTeam           *Team
 	AwayTeam           *Team
 	HomeTeamDifficulty int
 	AwayTeamDifficulty int
 	DifficultyMajority int
+	// KickoffTime is zero until the fixture is scheduled.
+	KickoffTime time.Time
+	Started     bool
+	Finished    bool
+	Minutes     int
+	// HomeTeamScore and AwayTeamScore are 0 until the fixture starts.
+	HomeTeamScore int
+	AwayTeamScore int
+	Stats         []FixtureStat
+}
+
+// FixtureStat is one player's value of a stat in a fixture, e.g. their goals
+// or bonus.
+type FixtureStat struct {
+	// Identifier is the api's name for the stat, e.g. "goals_scored".
+	Identifier string
+	PlayerID   PlayerID
+	// Home is whether the player was on the home side.
+	Home  bool
+	Value int
+}
+
+// Result is the fixture's final score, ok only once it has finished.
+func (f *Fixture) Result() (homeGoals int, awayGoals int, ok bool) {
+	if !f.Finished {
+		return 0, 0, false
+	}
+	return f.HomeTeamScore, f.AwayTeamScore, true
+}
+
+// Scheduled is whether the fixture has a gameweek.
+func (f *Fixture) Scheduled() bool {
+	return f.Gameweek != nil
 }
 
 func (f *Fixture) Players() []Player {
@@ -30,3 +67,51 @@ func (f *Fixture) Players() []Player {
 	players = append(players, f.AwayTeam.Players...)
 	return players
 }
+
+// FixtureCounts is how many fixtures each team plays in a gameweek.
+type FixtureCounts map[TeamID]int
+
+// CountFixtures counts each team's fixtures in each gameweek. Fixtures without
+// a gameweek aren't counted.
+func CountFixtures(fixtures []*Fixture) map[GameweekID]FixtureCounts {
+	counts := make(map[GameweekID]FixtureCounts, 0)
+	for _, fixture := range fixtures {
+		if !fixture.Scheduled() {
+			continue
+		}
+		gameweekCounts, ok := counts[fixture.Gameweek.ID]
+		if !ok {
+			gameweekCounts = make(FixtureCounts, 0)
+			counts[fixture.Gameweek.ID] = gameweekCounts
+		}
+		if fixture.HomeTeam != nil {
+			gameweekCounts[fixture.HomeTeam.ID]++
+		}
+		if fixture.AwayTeam != nil {
+			gameweekCounts[fixture.AwayTeam.ID]++
+		}
+	}
+	return counts
+}
+
+// Blanks are the teams without a fixture.
+func (c FixtureCounts) Blanks(teams []*Team) []*Team {
+	blanks := make([]*Team, 0)
+	for _, team := range teams {
+		if c[team.ID] == 0 {
+			blanks = append(blanks, team)
+		}
+	}
+	return blanks
+}
+
+// Doubles are the teams with more than one fixture.
+func (c FixtureCounts) Doubles(teams []*Team) []*Team {
+	doubles := make([]*Team, 0)
+	for _, team := range teams {
+		if c[team.ID] > 1 {
+			doubles = append(doubles, team)
+		}
+	}
+	return doubles
+}
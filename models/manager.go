@@ -7,3 +7,11 @@ type ManagerPick struct {
 	IsCaptain     bool
 	IsViceCaptain bool
 }
+
+// ManagerGameweek is the state of a manager's team in a gameweek.
+type ManagerGameweek struct {
+	ManagerID  int
+	GameweekID GameweekID
+	// Bank is the money left to spend, in £m.
+	Bank float32
+}
@@ -63,6 +63,37 @@ type Player struct {
 	ChanceOfPlaying  PlayerRoundProbability
 	MostCaptained    bool
 	PickedPercentage float32
+	News             string
+}
+
+// PlayerSnapshot is a player's form, cost, ownership and season totals as they
+// stood in a given gameweek.
+type PlayerSnapshot struct {
+	PlayerID         PlayerID
+	GameweekID       GameweekID
+	Form             float32
+	PointsPerGame    float32
+	TotalPoints      int
+	Cost             string
+	RawCost          float32
+	Stats            PlayerStats
+	PickedPercentage float32
+	News             string
+}
+
+func (p *Player) Snapshot(gameweekID GameweekID) PlayerSnapshot {
+	return PlayerSnapshot{
+		PlayerID:         p.ID,
+		GameweekID:       gameweekID,
+		Form:             p.Form,
+		PointsPerGame:    p.PointsPerGame,
+		TotalPoints:      p.TotalPoints,
+		Cost:             p.Cost,
+		RawCost:          p.RawCost,
+		Stats:            p.Stats,
+		PickedPercentage: p.PickedPercentage,
+		News:             p.News,
+	}
 }
 
 func (p *Player) FormOverCost() float32 {
@@ -79,45 +110,26 @@ func (p *Player) PointsOverCost() float32 {
 	return float32(p.TotalPoints) / p.RawCost
 }
 
-// goals & assists
-func (p *Player) AttackingPoints() float32 {
-	if len(p.History) == 0 {
-		return 0
-	}
-	history := make([]PlayerFixture, 0)
+// Saves adds up the player's saves from their history, which is empty in a
+// database imported without one.
+func (p *Player) Saves() float32 {
+	saves := 0
 	for _, fixture := range p.History {
-		history = append(history, fixture)
-	}
-	goalPoints := 0
-	assistPoints := 0
-	switch p.Type.ID {
-	case PTGoalkeeper:
-		for _, fixture := range history {
-			goalPoints += fixture.GoalsScored * 10
-			assistPoints += fixture.Assists * 3
-		}
-	case PTDefender:
-		for _, fixture := range history {
-			goalPoints += fixture.GoalsScored * 6
-			assistPoints += fixture.Assists * 3
-		}
-	case PTMidfielder:
-		for _, fixture := range history {
-			goalPoints += fixture.GoalsScored * 5
-			assistPoints += fixture.Assists * 3
-		}
-	case PTForward:
-		for _, fixture := range history {
-			goalPoints += fixture.GoalsScored * 4
-			assistPoints += fixture.Assists * 3
-		}
+		saves += fixture.Saves
 	}
-	return float32(goalPoints) + float32(assistPoints)
+	return float32(saves)
 }
 
+// goals & assists
+func (p *Player) AttackingPoints() float32 {
+	return float32(p.PointsBreakdown(DefaultScoringRules).Attacking())
+}
+
+// CleanSheets counts the player's clean sheets from their history, or is the
+// season total without one.
 func (p *Player) CleanSheets() float32 {
 	if len(p.History) == 0 {
-		return 0
+		return float32(p.Stats.CleanSheets)
 	}
 	cleanSheetPoints := 0
 	for _, fixture := range p.History {
@@ -128,59 +140,10 @@ func (p *Player) CleanSheets() float32 {
 	return float32(cleanSheetPoints)
 }
 
-// goals & assists
-func (p *Player) AttackingForm(weeks int) float32 {
-	if weeks == 0 || len(p.History) == 0 {
-		return 0
-	}
-	history := make([]PlayerFixture, 0)
-	for _, fixture := range p.History {
-		history = append(history, fixture)
-	}
-	sort.Slice(history, func(i, j int) bool {
-		return history[i].FixtureID > history[j].FixtureID
-	})
-	var lastPlayed []PlayerFixture
-	if weeks > len(p.History) {
-		lastPlayed = history
-	} else {
-		lastPlayed = history[len(history)-weeks:]
-	}
-	goalPoints := 0
-	assistPoints := 0
-	switch p.Type.ID {
-	case PTGoalkeeper:
-		for _, fixture := range lastPlayed {
-			goalPoints += fixture.GoalsScored * 10
-			assistPoints += fixture.Assists * 3
-		}
-	case PTDefender:
-		for _, fixture := range lastPlayed {
-			goalPoints += fixture.GoalsScored * 6
-			assistPoints += fixture.Assists * 3
-		}
-	case PTMidfielder:
-		for _, fixture := range lastPlayed {
-			goalPoints += fixture.GoalsScored * 5
-			assistPoints += fixture.Assists * 3
-		}
-	case PTForward:
-		for _, fixture := range lastPlayed {
-			goalPoints += fixture.GoalsScored * 4
-			assistPoints += fixture.Assists * 3
-		}
-	}
-	return (float32(goalPoints) + float32(assistPoints)) / float32(weeks)
-}
-
-// clean sheets
-func (p *Player) DefendingForm(weeks int) float32 {
-	return 0
-}
-
 type PlayerFixture struct {
 	FixtureID   FixtureID
 	PlayerID    PlayerID
+	GameweekID  GameweekID
 	Minutes     int
 	Played      bool
 	Points      int
@@ -191,4 +154,28 @@ type PlayerFixture struct {
 	Bonus       int
 	CleanSheet  bool
 	WasHome     bool
+
+	GoalsConceded         int
+	Saves                 int
+	PenaltiesSaved        int
+	PenaltiesMissed       int
+	OwnGoals              int
+	DefensiveContribution int
+	Starts                int
+}
+
+// SortedHistory returns the player's fixtures in the order they were played,
+// oldest first.
+func (p *Player) SortedHistory() []PlayerFixture {
+	history := make([]PlayerFixture, 0, len(p.History))
+	for _, fixture := range p.History {
+		history = append(history, fixture)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		if history[i].GameweekID != history[j].GameweekID {
+			return history[i].GameweekID < history[j].GameweekID
+		}
+		return history[i].FixtureID < history[j].FixtureID
+	})
+	return history
 }
@@ -0,0 +1,141 @@
+package models
+
+// DefaultMinMinutes is the minutes a player needs before their rates are
+// worth comparing, three full matches.
+const DefaultMinMinutes = 270
+
+// Rates are a player's season totals divided by 90 minutes played or by
+// matches started.
+type Rates struct {
+	Goals            float32
+	Assists          float32
+	GoalInvolvements float32
+	Bonus            float32
+	Points           float32
+	CleanSheets      float32
+	Saves            float32
+}
+
+// seasonTotals are the totals rates are worked out from.
+type seasonTotals struct {
+	minutes     int
+	starts      int
+	goals       int
+	assists     int
+	bonus       int
+	points      int
+	cleanSheets int
+	saves       int
+}
+
+// totals adds up the player's history, falling back to their season stats
+// when the history hasn't been loaded.
+func (p *Player) totals() seasonTotals {
+	if len(p.History) == 0 {
+		return seasonTotals{
+			minutes:     p.Stats.Minutes,
+			starts:      p.Stats.Starts,
+			goals:       p.Stats.Goals,
+			assists:     p.Stats.Assists,
+			bonus:       p.Stats.Bonus,
+			points:      p.TotalPoints,
+			cleanSheets: p.Stats.CleanSheets,
+		}
+	}
+
+	var totals seasonTotals
+	for _, fixture := range p.History {
+		totals.minutes += fixture.Minutes
+		totals.starts += fixture.Starts
+		totals.goals += fixture.GoalsScored
+		totals.assists += fixture.Assists
+		totals.bonus += fixture.Bonus
+		totals.points += fixture.Points
+		totals.saves += fixture.Saves
+		if fixture.CleanSheet {
+			totals.cleanSheets++
+		}
+	}
+	return totals
+}
+
+func (t seasonTotals) add(other seasonTotals) seasonTotals {
+	return seasonTotals{
+		minutes:     t.minutes + other.minutes,
+		starts:      t.starts + other.starts,
+		goals:       t.goals + other.goals,
+		assists:     t.assists + other.assists,
+		bonus:       t.bonus + other.bonus,
+		points:      t.points + other.points,
+		cleanSheets: t.cleanSheets + other.cleanSheets,
+		saves:       t.saves + other.saves,
+	}
+}
+
+func (t seasonTotals) per(divisor float32) Rates {
+	if divisor <= 0 {
+		return Rates{}
+	}
+	return Rates{
+		Goals:            float32(t.goals) / divisor,
+		Assists:          float32(t.assists) / divisor,
+		GoalInvolvements: float32(t.goals+t.assists) / divisor,
+		Bonus:            float32(t.bonus) / divisor,
+		Points:           float32(t.points) / divisor,
+		CleanSheets:      float32(t.cleanSheets) / divisor,
+		Saves:            float32(t.saves) / divisor,
+	}
+}
+
+// Per90 is the player's rates per 90 minutes played.
+func (p *Player) Per90() Rates {
+	totals := p.totals()
+	return totals.per(float32(totals.minutes) / 90)
+}
+
+// CombinedPer90 is the players' rates per 90 minutes taken together, and
+// false when none of them has played.
+func CombinedPer90(players []Player) (Rates, bool) {
+	var totals seasonTotals
+	for _, player := range players {
+		totals = totals.add(player.totals())
+	}
+	if totals.minutes == 0 {
+		return Rates{}, false
+	}
+	return totals.per(float32(totals.minutes) / 90), true
+}
+
+// PerStart is the player's rates per match started.
+func (p *Player) PerStart() Rates {
+	totals := p.totals()
+	return totals.per(float32(totals.starts))
+}
+
+// Appearances counts the fixtures in the player's history they played in.
+func (p *Player) Appearances() int {
+	appearances := 0
+	for _, fixture := range p.History {
+		if fixture.Minutes > 0 {
+			appearances++
+		}
+	}
+	return appearances
+}
+
+// HasMinutes reports whether the player has played enough for their rates to
+// be compared with other players'.
+func (p *Player) HasMinutes(minMinutes int) bool {
+	return p.totals().minutes >= minMinutes
+}
+
+// WithMinutes returns the players who have played at least minMinutes.
+func WithMinutes(players []Player, minMinutes int) []Player {
+	filtered := make([]Player, 0)
+	for _, player := range players {
+		if player.HasMinutes(minMinutes) {
+			filtered = append(filtered, player)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,242 @@
+package models
+
+// ScoringRules are the points awarded for each action in a season. Values
+// keyed by position fall back to 0 for positions that aren't listed.
+type ScoringRules struct {
+	Season string
+
+	// appearance points, Long for playing at least LongAppearanceMinutes
+	ShortAppearance       int
+	LongAppearance        int
+	LongAppearanceMinutes int
+
+	Goal   map[PlayerTypeID]int
+	Assist int
+
+	// clean sheets only count after CleanSheetMinutes
+	CleanSheet        map[PlayerTypeID]int
+	CleanSheetMinutes int
+
+	// GoalsConceded is awarded for every GoalsConcededPer goals conceded
+	GoalsConceded    map[PlayerTypeID]int
+	GoalsConcededPer int
+
+	// Save is awarded for every SavesPer saves
+	Save     int
+	SavesPer int
+
+	// DefensiveContribution is awarded once a player reaches the position's
+	// threshold of clearances, blocks, interceptions, tackles (and recoveries
+	// outside defence). A threshold of 0 means none is awarded.
+	DefensiveContribution          int
+	DefensiveContributionThreshold map[PlayerTypeID]int
+
+	PenaltySaved  int
+	PenaltyMissed int
+	YellowCard    int
+	RedCard       int
+	OwnGoal       int
+}
+
+var Rules2024 = ScoringRules{
+	Season:                "2024/25",
+	ShortAppearance:       1,
+	LongAppearance:        2,
+	LongAppearanceMinutes: 60,
+	Goal: map[PlayerTypeID]int{
+		PTGoalkeeper: 10,
+		PTDefender:   6,
+		PTMidfielder: 5,
+		PTForward:    4,
+	},
+	Assist: 3,
+	CleanSheet: map[PlayerTypeID]int{
+		PTGoalkeeper: 4,
+		PTDefender:   4,
+		PTMidfielder: 1,
+	},
+	CleanSheetMinutes: 60,
+	GoalsConceded: map[PlayerTypeID]int{
+		PTGoalkeeper: -1,
+		PTDefender:   -1,
+	},
+	GoalsConcededPer: 2,
+	Save:             1,
+	SavesPer:         3,
+	PenaltySaved:     5,
+	PenaltyMissed:    -2,
+	YellowCard:       -1,
+	RedCard:          -3,
+	OwnGoal:          -2,
+}
+
+// Rules2025 adds defensive contribution points to the 2024/25 rules.
+var Rules2025 = func() ScoringRules {
+	rules := Rules2024
+	rules.Season = "2025/26"
+	rules.DefensiveContribution = 2
+	rules.DefensiveContributionThreshold = map[PlayerTypeID]int{
+		PTDefender:   10,
+		PTMidfielder: 12,
+		PTForward:    12,
+	}
+	return rules
+}()
+
+// ScoringRulesBySeason lists the rules of every season we know about.
+var ScoringRulesBySeason = map[string]ScoringRules{
+	Rules2024.Season: Rules2024,
+	Rules2025.Season: Rules2025,
+}
+
+// DefaultScoringRules are the rules of the latest season we know about.
+var DefaultScoringRules = Rules2025
+
+// PointsBreakdown is a player's points split by where they came from.
+type PointsBreakdown struct {
+	Appearance            int
+	Goals                 int
+	Assists               int
+	CleanSheet            int
+	GoalsConceded         int
+	Saves                 int
+	DefensiveContribution int
+	PenaltiesSaved        int
+	PenaltiesMissed       int
+	YellowCards           int
+	RedCards              int
+	OwnGoals              int
+	Bonus                 int
+}
+
+func (b PointsBreakdown) Total() int {
+	return b.Appearance + b.Goals + b.Assists + b.CleanSheet + b.GoalsConceded +
+		b.Saves + b.DefensiveContribution + b.PenaltiesSaved + b.PenaltiesMissed +
+		b.YellowCards + b.RedCards + b.OwnGoals + b.Bonus
+}
+
+// Attacking is the points from goals and assists.
+func (b PointsBreakdown) Attacking() int {
+	return b.Goals + b.Assists
+}
+
+func (b PointsBreakdown) Add(other PointsBreakdown) PointsBreakdown {
+	return PointsBreakdown{
+		Appearance:            b.Appearance + other.Appearance,
+		Goals:                 b.Goals + other.Goals,
+		Assists:               b.Assists + other.Assists,
+		CleanSheet:            b.CleanSheet + other.CleanSheet,
+		GoalsConceded:         b.GoalsConceded + other.GoalsConceded,
+		Saves:                 b.Saves + other.Saves,
+		DefensiveContribution: b.DefensiveContribution + other.DefensiveContribution,
+		PenaltiesSaved:        b.PenaltiesSaved + other.PenaltiesSaved,
+		PenaltiesMissed:       b.PenaltiesMissed + other.PenaltiesMissed,
+		YellowCards:           b.YellowCards + other.YellowCards,
+		RedCards:              b.RedCards + other.RedCards,
+		OwnGoals:              b.OwnGoals + other.OwnGoals,
+		Bonus:                 b.Bonus + other.Bonus,
+	}
+}
+
+// Score works out the points a player of the given position earned in a
+// fixture.
+func (r ScoringRules) Score(fixture PlayerFixture, position PlayerTypeID) PointsBreakdown {
+	var points PointsBreakdown
+	if fixture.Minutes <= 0 {
+		return points
+	}
+
+	if fixture.Minutes >= r.LongAppearanceMinutes {
+		points.Appearance = r.LongAppearance
+	} else {
+		points.Appearance = r.ShortAppearance
+	}
+	points.Goals = fixture.GoalsScored * r.Goal[position]
+	points.Assists = fixture.Assists * r.Assist
+	if fixture.CleanSheet && fixture.Minutes >= r.CleanSheetMinutes {
+		points.CleanSheet = r.CleanSheet[position]
+	}
+	if r.GoalsConcededPer > 0 {
+		points.GoalsConceded = fixture.GoalsConceded / r.GoalsConcededPer * r.GoalsConceded[position]
+	}
+	if r.SavesPer > 0 {
+		points.Saves = fixture.Saves / r.SavesPer * r.Save
+	}
+	if threshold := r.DefensiveContributionThreshold[position]; threshold > 0 && fixture.DefensiveContribution >= threshold {
+		points.DefensiveContribution = r.DefensiveContribution
+	}
+	points.PenaltiesSaved = fixture.PenaltiesSaved * r.PenaltySaved
+	points.PenaltiesMissed = fixture.PenaltiesMissed * r.PenaltyMissed
+	points.YellowCards = fixture.YellowCards * r.YellowCard
+	points.RedCards = fixture.RedCards * r.RedCard
+	points.OwnGoals = fixture.OwnGoals * r.OwnGoal
+	points.Bonus = fixture.Bonus
+
+	return points
+}
+
+// FixtureReconciliation compares the points Score works out for a fixture
+// with the points the API reported.
+type FixtureReconciliation struct {
+	FixtureID  FixtureID
+	Breakdown  PointsBreakdown
+	Calculated int
+	Reported   int
+}
+
+func (f FixtureReconciliation) Difference() int {
+	return f.Reported - f.Calculated
+}
+
+// Reconciliation is a player's points from every fixture in their history.
+type Reconciliation struct {
+	Breakdown  PointsBreakdown
+	Calculated int
+	Reported   int
+	Fixtures   []FixtureReconciliation
+}
+
+func (r Reconciliation) Difference() int {
+	return r.Reported - r.Calculated
+}
+
+// Mismatches are the fixtures whose calculated points differ from the API's.
+func (r Reconciliation) Mismatches() []FixtureReconciliation {
+	mismatches := make([]FixtureReconciliation, 0)
+	for _, fixture := range r.Fixtures {
+		if fixture.Difference() != 0 {
+			mismatches = append(mismatches, fixture)
+		}
+	}
+	return mismatches
+}
+
+// PointsBreakdown is the player's points from every fixture in their history.
+func (p *Player) PointsBreakdown(rules ScoringRules) PointsBreakdown {
+	var breakdown PointsBreakdown
+	for _, fixture := range p.History {
+		breakdown = breakdown.Add(rules.Score(fixture, p.Type.ID))
+	}
+	return breakdown
+}
+
+// Reconcile scores every fixture in the player's history, oldest first, and
+// compares each with the API's points for it.
+func (p *Player) Reconcile(rules ScoringRules) Reconciliation {
+	reconciliation := Reconciliation{
+		Fixtures: make([]FixtureReconciliation, 0, len(p.History)),
+	}
+	for _, fixture := range p.SortedHistory() {
+		breakdown := rules.Score(fixture, p.Type.ID)
+		reconciliation.Breakdown = reconciliation.Breakdown.Add(breakdown)
+		reconciliation.Calculated += breakdown.Total()
+		reconciliation.Reported += fixture.Points
+		reconciliation.Fixtures = append(reconciliation.Fixtures, FixtureReconciliation{
+			FixtureID:  fixture.FixtureID,
+			Breakdown:  breakdown,
+			Calculated: breakdown.Total(),
+			Reported:   fixture.Points,
+		})
+	}
+	return reconciliation
+}
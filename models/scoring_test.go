@@ -0,0 +1,74 @@
+package models
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		position PlayerTypeID
+		fixture  PlayerFixture
+		want     PointsBreakdown
+		total    int
+	}{
+		{
+			name:     "goalkeeper clean sheet, saves and a penalty save",
+			position: PTGoalkeeper,
+			fixture:  PlayerFixture{Minutes: 90, CleanSheet: true, Saves: 7, PenaltiesSaved: 1, Bonus: 3},
+			want:     PointsBreakdown{Appearance: 2, CleanSheet: 4, Saves: 2, PenaltiesSaved: 5, Bonus: 3},
+			total:    16,
+		},
+		{
+			name:     "goalkeeper goal and goals conceded",
+			position: PTGoalkeeper,
+			fixture:  PlayerFixture{Minutes: 90, GoalsScored: 1, GoalsConceded: 4, Saves: 2},
+			want:     PointsBreakdown{Appearance: 2, Goals: 10, GoalsConceded: -2},
+			total:    10,
+		},
+		{
+			name:     "defender goal, assist, card and defensive contribution",
+			position: PTDefender,
+			fixture:  PlayerFixture{Minutes: 90, GoalsScored: 1, Assists: 1, GoalsConceded: 3, YellowCards: 1, DefensiveContribution: 10},
+			want:     PointsBreakdown{Appearance: 2, Goals: 6, Assists: 3, GoalsConceded: -1, YellowCards: -1, DefensiveContribution: 2},
+			total:    11,
+		},
+		{
+			name:     "defender clean sheet under the minutes",
+			position: PTDefender,
+			fixture:  PlayerFixture{Minutes: 45, CleanSheet: true},
+			want:     PointsBreakdown{Appearance: 1},
+			total:    1,
+		},
+		{
+			name:     "midfielder goals, clean sheet and bonus",
+			position: PTMidfielder,
+			fixture:  PlayerFixture{Minutes: 75, GoalsScored: 2, CleanSheet: true, Bonus: 2, DefensiveContribution: 11},
+			want:     PointsBreakdown{Appearance: 2, Goals: 10, CleanSheet: 1, Bonus: 2},
+			total:    15,
+		},
+		{
+			name:     "forward goal, red card and own goal",
+			position: PTForward,
+			fixture:  PlayerFixture{Minutes: 30, GoalsScored: 1, CleanSheet: true, RedCards: 1, OwnGoals: 1, PenaltiesMissed: 1},
+			want:     PointsBreakdown{Appearance: 1, Goals: 4, RedCards: -3, OwnGoals: -2, PenaltiesMissed: -2},
+			total:    -2,
+		},
+		{
+			name:     "no minutes scores nothing",
+			position: PTForward,
+			fixture:  PlayerFixture{GoalsScored: 1, Bonus: 3},
+			want:     PointsBreakdown{},
+			total:    0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Rules2025.Score(test.fixture, test.position)
+			if got != test.want {
+				t.Errorf("Score() = %+v, want %+v", got, test.want)
+			}
+			if got.Total() != test.total {
+				t.Errorf("Total() = %d, want %d", got.Total(), test.total)
+			}
+		})
+	}
+}
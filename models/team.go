@@ -15,6 +15,18 @@ type Team struct {
 	Fixtures  []Fixture
 }
 
+// FixturesIn is the team's fixtures in a gameweek: none in a blank gameweek
+// and two or more in a double.
+func (t *Team) FixturesIn(gameweekID GameweekID) []Fixture {
+	fixtures := make([]Fixture, 0)
+	for _, fixture := range t.Fixtures {
+		if fixture.Scheduled() && fixture.Gameweek.ID == gameweekID {
+			fixtures = append(fixtures, fixture)
+		}
+	}
+	return fixtures
+}
+
 type StartingPlayer struct {
 	Player       Player
 	Fixture      Fixture
@@ -27,10 +39,8 @@ type StartingEleven map[string][]StartingPlayer
 
 func (se StartingEleven) PlayerCount() int {
 	count := 0
-	for position := range se {
-		for range position {
-			count++
-		}
+	for _, players := range se {
+		count += len(players)
 	}
 	return count
 }
@@ -0,0 +1,323 @@
+package projection
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/strength"
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultHorizon is how many gameweeks ahead to project.
+	DefaultHorizon = 5
+	// DefaultFormWeeks is how many recent gameweeks minutes are judged on.
+	DefaultFormWeeks = 5
+
+	// ratePriorMinutes is how many minutes of the position's average rates a
+	// player's own rates are blended with, so a single cameo doesn't project
+	// like a season.
+	ratePriorMinutes = 450
+)
+
+// FixtureProjection is a player's expected points from one fixture.
+type FixtureProjection struct {
+	Fixture  *models.Fixture
+	Opponent *models.Team
+	Home     bool
+	// Difficulty is from team strength, on the official 1 (easiest) to 5
+	// scale.
+	Difficulty float32
+	// Chance is how likely the player is to play at all.
+	Chance  float32
+	Minutes float32
+	Points  float32
+}
+
+// GameweekProjection is a player's expected points from every fixture in a
+// gameweek. Blank gameweeks have no fixtures and no points.
+type GameweekProjection struct {
+	GameweekID models.GameweekID
+	Fixtures   []FixtureProjection
+	Points     float32
+}
+
+// Chance is how likely the player is to play in at least one of the
+// gameweek's fixtures.
+func (g GameweekProjection) Chance() float32 {
+	missing := float32(1)
+	for _, fixture := range g.Fixtures {
+		missing *= 1 - fixture.Chance
+	}
+	return 1 - missing
+}
+
+type Projection struct {
+	Player    models.Player
+	Gameweeks []GameweekProjection
+	Points    float32
+}
+
+// PointsIn is the projected points for one gameweek, 0 if it wasn't
+// projected.
+func (p Projection) PointsIn(gameweekID models.GameweekID) float32 {
+	for _, gameweek := range p.Gameweeks {
+		if gameweek.GameweekID == gameweekID {
+			return gameweek.Points
+		}
+	}
+	return 0
+}
+
+// Model projects expected points (xP) from players' per-90 rates, how likely
+// they are to play and how many goals each side is expected to score, from
+// team strength ratings rather than the official difficulty.
+type Model struct {
+	Rules     models.ScoringRules
+	FormWeeks int
+
+	// Strength rates each team's attack and defence for the fixtures.
+	Strength *strength.Ratings
+
+	fixturesByTeam map[models.TeamID][]*models.Fixture
+	positionRates  map[models.PlayerTypeID]models.Rates
+	// teamMatches is how many matches each team has played, for judging
+	// players without a history from their season stats.
+	teamMatches map[models.TeamID]float32
+}
+
+// NewModel builds a model from every player, whose histories give average
+// rates, the teams' strength ratings and the fixtures to project.
+func NewModel(players []models.Player, fixtures []*models.Fixture, ratings *strength.Ratings) *Model {
+	model := &Model{
+		Rules:          models.DefaultScoringRules,
+		FormWeeks:      DefaultFormWeeks,
+		Strength:       ratings,
+		fixturesByTeam: make(map[models.TeamID][]*models.Fixture, 0),
+		positionRates:  positionRates(players),
+		teamMatches:    teamMatches(players, ratings),
+	}
+
+	for _, fixture := range fixtures {
+		if !fixture.Scheduled() {
+			continue
+		}
+		model.fixturesByTeam[fixture.HomeTeam.ID] = append(model.fixturesByTeam[fixture.HomeTeam.ID], fixture)
+		model.fixturesByTeam[fixture.AwayTeam.ID] = append(model.fixturesByTeam[fixture.AwayTeam.ID], fixture)
+	}
+
+	return model
+}
+
+// Project works out a player's expected points for each gameweek from one to
+// another (inclusive).
+func (m *Model) Project(player models.Player, fromGameweek models.GameweekID, toGameweek models.GameweekID) Projection {
+	projection := Projection{
+		Player:    player,
+		Gameweeks: make([]GameweekProjection, 0),
+	}
+	if player.Team == nil {
+		return projection
+	}
+
+	rates := m.rates(player)
+	minutes := m.minutes(player)
+
+	for gameweekID := fromGameweek; gameweekID <= toGameweek; gameweekID++ {
+		gameweek := GameweekProjection{
+			GameweekID: gameweekID,
+			Fixtures:   make([]FixtureProjection, 0),
+		}
+		chance := float32(1)
+		if c, ok := player.ChanceOfPlaying[gameweekID]; ok {
+			chance = c
+		}
+		for _, fixture := range m.fixturesByTeam[player.Team.ID] {
+			if fixture.Gameweek.ID != gameweekID {
+				continue
+			}
+			fixtureProjection := m.projectFixture(player, fixture, rates, minutes.scaled(chance))
+			gameweek.Fixtures = append(gameweek.Fixtures, fixtureProjection)
+			gameweek.Points += fixtureProjection.Points
+		}
+		projection.Gameweeks = append(projection.Gameweeks, gameweek)
+		projection.Points += gameweek.Points
+	}
+
+	return projection
+}
+
+// ProjectAll projects every player, highest projected points first.
+func (m *Model) ProjectAll(players []models.Player, fromGameweek models.GameweekID, toGameweek models.GameweekID) []Projection {
+	projections := make([]Projection, 0, len(players))
+	for _, player := range players {
+		projections = append(projections, m.Project(player, fromGameweek, toGameweek))
+	}
+	sort.SliceStable(projections, func(i, j int) bool {
+		return projections[i].Points > projections[j].Points
+	})
+	return projections
+}
+
+func (m *Model) projectFixture(player models.Player, fixture *models.Fixture, rates models.Rates, minutes minutesLikelihood) FixtureProjection {
+	home := fixture.HomeTeam.ID == player.Team.ID
+	opponent := fixture.AwayTeam
+	if !home {
+		opponent = fixture.HomeTeam
+	}
+
+	position := player.Type.ID
+	rules := m.Rules
+	expectedScored, expectedConceded := m.Strength.FixtureGoals(fixture, player.Team.ID)
+	// the player's rates already reflect how many their team usually scores,
+	// so only how this fixture compares with that moves their goals
+	attackFactor := 1.0
+	if usual := m.usualGoals(player.Team.ID); usual > 0 {
+		attackFactor = expectedScored / usual
+	}
+
+	nineties := float64(minutes.played * minutes.minutesWhenPlaying / 90)
+
+	var points float64
+	points += float64(minutes.sixty)*float64(rules.LongAppearance) + float64(minutes.played-minutes.sixty)*float64(rules.ShortAppearance)
+	points += float64(rates.Goals) * nineties * attackFactor * float64(rules.Goal[position])
+	points += float64(rates.Assists) * nineties * attackFactor * float64(rules.Assist)
+	points += float64(minutes.sixty) * math.Exp(-expectedConceded) * float64(rules.CleanSheet[position])
+	if rules.GoalsConcededPer > 0 {
+		points += float64(minutes.sixty) * expectedConceded / float64(rules.GoalsConcededPer) * float64(rules.GoalsConceded[position])
+	}
+	if rules.SavesPer > 0 {
+		// keepers facing better attacks make more saves
+		saves := float64(rates.Saves) * nineties * expectedConceded / m.Strength.AverageGoals()
+		points += saves / float64(rules.SavesPer) * float64(rules.Save)
+	}
+	points += float64(rates.Bonus) * nineties
+
+	return FixtureProjection{
+		Fixture:    fixture,
+		Opponent:   opponent,
+		Home:       home,
+		Difficulty: float32(m.Strength.Difficulty(fixture, player.Team.ID)),
+		Chance:     minutes.played,
+		Minutes:    minutes.played * minutes.minutesWhenPlaying,
+		Points:     float32(points),
+	}
+}
+
+// usualGoals is how many goals a team scores in an average match, half at home
+// and half away.
+func (m *Model) usualGoals(teamID models.TeamID) float64 {
+	team := m.Strength.Team(teamID)
+	return (m.Strength.HomeGoals*team.Home.Attack + m.Strength.AwayGoals*team.Away.Attack) / 2
+}
+
+// rates blends the player's per-90 rates with their position's average, by
+// how many minutes they've played.
+func (m *Model) rates(player models.Player) models.Rates {
+	average := m.positionRates[player.Type.ID]
+	minutes := 0
+	for _, fixture := range player.History {
+		minutes += fixture.Minutes
+	}
+	if len(player.History) == 0 {
+		minutes = player.Stats.Minutes
+	}
+	weight := float32(minutes) / float32(minutes+ratePriorMinutes)
+	own := player.Per90()
+	blend := func(own float32, average float32) float32 {
+		return weight*own + (1-weight)*average
+	}
+	return models.Rates{
+		Goals:            blend(own.Goals, average.Goals),
+		Assists:          blend(own.Assists, average.Assists),
+		GoalInvolvements: blend(own.GoalInvolvements, average.GoalInvolvements),
+		Bonus:            blend(own.Bonus, average.Bonus),
+		Points:           blend(own.Points, average.Points),
+		CleanSheets:      blend(own.CleanSheets, average.CleanSheets),
+		Saves:            blend(own.Saves, average.Saves),
+	}
+}
+
+// minutesLikelihood is how likely a player is to play in a fixture, and for
+// how long.
+type minutesLikelihood struct {
+	played             float32
+	sixty              float32
+	minutesWhenPlaying float32
+}
+
+func (l minutesLikelihood) scaled(chance float32) minutesLikelihood {
+	l.played *= chance
+	l.sixty *= chance
+	return l
+}
+
+// minutes judges how likely a player is to play from their recent gameweeks.
+func (m *Model) minutes(player models.Player) minutesLikelihood {
+	var likelihood minutesLikelihood
+	fixtures := 0
+	minutesPlayed := 0
+	for _, gameweek := range player.RecentGameweeks(m.FormWeeks) {
+		for _, fixture := range gameweek {
+			fixtures++
+			if fixture.Minutes > 0 {
+				likelihood.played++
+				minutesPlayed += fixture.Minutes
+			}
+			if fixture.Minutes >= m.Rules.LongAppearanceMinutes {
+				likelihood.sixty++
+			}
+		}
+	}
+	if fixtures == 0 {
+		return m.seasonMinutes(player)
+	}
+	if likelihood.played == 0 {
+		return minutesLikelihood{}
+	}
+	likelihood.minutesWhenPlaying = float32(minutesPlayed) / likelihood.played
+	likelihood.played /= float32(fixtures)
+	likelihood.sixty /= float32(fixtures)
+	return likelihood
+}
+
+// seasonMinutes judges how likely a player without a history is to play from
+// their season stats: their appearances out of their team's matches, starts
+// for playing sixty minutes and their minutes per appearance.
+func (m *Model) seasonMinutes(player models.Player) minutesLikelihood {
+	appearances := seasonAppearances(player)
+	if appearances == 0 || player.Stats.Minutes == 0 || player.Team == nil {
+		return minutesLikelihood{}
+	}
+	matches := max(m.teamMatches[player.Team.ID], appearances)
+	return minutesLikelihood{
+		played:             appearances / matches,
+		sixty:              min(float32(player.Stats.Starts), appearances) / matches,
+		minutesWhenPlaying: float32(player.Stats.Minutes) / appearances,
+	}
+}
+
+// seasonAppearances is the matches the player has played in, from their
+// history or, without one, the matches played stored with their stats.
+func seasonAppearances(player models.Player) float32 {
+	if len(player.History) > 0 {
+		return float32(player.Appearances())
+	}
+	return max(player.Stats.MatchesPlayed, float32(player.Stats.Starts))
+}
+
+// teamMatches is how many matches each team has results for, or at least as
+// many as any of its players has played in.
+func teamMatches(players []models.Player, ratings *strength.Ratings) map[models.TeamID]float32 {
+	matches := make(map[models.TeamID]float32, 0)
+	for _, player := range players {
+		if player.Team == nil {
+			continue
+		}
+		teamID := player.Team.ID
+		if _, ok := matches[teamID]; !ok && ratings != nil {
+			matches[teamID] = float32(ratings.Team(teamID).Matches)
+		}
+		matches[teamID] = max(matches[teamID], seasonAppearances(player))
+	}
+	return matches
+}
@@ -0,0 +1,47 @@
+package projection
+
+import (
+	"better-fantasy/models"
+	"better-fantasy/strength"
+	"math"
+	"testing"
+)
+
+func TestMinutesWithoutHistory(t *testing.T) {
+	team := &models.Team{ID: 1}
+	opponent := &models.Team{ID: 2}
+	midfielder := models.PlayerType{ID: models.PTMidfielder}
+	regular := models.Player{ID: 1, Team: team, Type: midfielder, Stats: models.PlayerStats{Minutes: 900, Starts: 10, MatchesPlayed: 10}}
+	substitute := models.Player{ID: 2, Team: team, Type: midfielder, Stats: models.PlayerStats{Minutes: 150, Starts: 1, MatchesPlayed: 5}}
+	unused := models.Player{ID: 3, Team: team, Type: midfielder}
+	players := []models.Player{regular, substitute, unused}
+
+	fixture := &models.Fixture{ID: 1, Gameweek: &models.Gameweek{ID: 11}, HomeTeam: team, AwayTeam: opponent}
+	model := NewModel(players, []*models.Fixture{fixture}, strength.Fit(nil, strength.DefaultHalfLife))
+
+	tests := []struct {
+		name   string
+		player models.Player
+		want   minutesLikelihood
+	}{
+		{name: "regular starter", player: regular, want: minutesLikelihood{played: 1, sixty: 1, minutesWhenPlaying: 90}},
+		{name: "substitute", player: substitute, want: minutesLikelihood{played: 0.5, sixty: 0.1, minutesWhenPlaying: 30}},
+		{name: "never played", player: unused, want: minutesLikelihood{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := model.minutes(test.player)
+			if !closeTo(got.played, test.want.played) || !closeTo(got.sixty, test.want.sixty) || !closeTo(got.minutesWhenPlaying, test.want.minutesWhenPlaying) {
+				t.Errorf("minutes() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+
+	if projection := model.Project(regular, 11, 11); projection.Points <= 0 {
+		t.Errorf("Project() = %.2f xP for a regular starter without a history, want more than 0", projection.Points)
+	}
+}
+
+func closeTo(got float32, want float32) bool {
+	return math.Abs(float64(got-want)) < 1e-5
+}
@@ -0,0 +1,19 @@
+package projection
+
+import "better-fantasy/models"
+
+// positionRates are the per-90 rates of each position as a whole.
+func positionRates(players []models.Player) map[models.PlayerTypeID]models.Rates {
+	byPosition := make(map[models.PlayerTypeID][]models.Player, 0)
+	for _, player := range players {
+		byPosition[player.Type.ID] = append(byPosition[player.Type.ID], player)
+	}
+
+	rates := make(map[models.PlayerTypeID]models.Rates, len(byPosition))
+	for position, positionPlayers := range byPosition {
+		if positionRates, ok := models.CombinedPer90(positionPlayers); ok {
+			rates[position] = positionRates
+		}
+	}
+	return rates
+}
@@ -0,0 +1,113 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"fmt"
+	"math"
+	"sort"
+)
+
+type CaptainMode string
+
+const (
+	// SafeCaptain favours players who reliably return their projection.
+	SafeCaptain CaptainMode = "safe"
+	// DifferentialCaptain favours big hauls few other managers will share.
+	DifferentialCaptain CaptainMode = "differential"
+)
+
+const (
+	// ceilingDeviations is how many standard deviations above the projection
+	// a player's ceiling is, roughly their 90th percentile.
+	ceilingDeviations = 1.28
+	// safetyWeight is how much each point of standard deviation costs a
+	// player in safe mode.
+	safetyWeight = 0.25
+	// varianceGameweeks is how many recent gameweeks variance is judged on.
+	varianceGameweeks = 10
+)
+
+func ParseCaptainMode(mode string) (CaptainMode, error) {
+	switch CaptainMode(mode) {
+	case SafeCaptain, DifferentialCaptain:
+		return CaptainMode(mode), nil
+	}
+	return "", fmt.Errorf("unknown captain mode '%s' (expected safe or differential)", mode)
+}
+
+type CaptainOption struct {
+	Player models.Player
+	// Points is the player's projected points, before doubling.
+	Points  float32
+	StdDev  float32
+	Ceiling float32
+	// EffectiveOwnership is the percentage of managers who get the player's
+	// points, counting captains twice.
+	EffectiveOwnership float32
+	Score              float32
+}
+
+// RankCaptains orders players by how good a captain they'd be in mode, given
+// their projected points for the gameweek.
+func RankCaptains(players []models.Player, projected map[models.PlayerID]float32, mode CaptainMode) []CaptainOption {
+	options := make([]CaptainOption, 0, len(players))
+	for _, player := range players {
+		points := projected[player.ID]
+		stdDev := pointsStdDev(player)
+		option := CaptainOption{
+			Player:             player,
+			Points:             points,
+			StdDev:             stdDev,
+			Ceiling:            points + ceilingDeviations*stdDev,
+			EffectiveOwnership: EffectiveOwnership(player),
+		}
+		switch mode {
+		case DifferentialCaptain:
+			// what a haul gains on the field, who get EO/100 of it against
+			// our two
+			option.Score = option.Ceiling * (1 - option.EffectiveOwnership/200)
+		default:
+			option.Score = points - safetyWeight*stdDev
+		}
+		options = append(options, option)
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].Score > options[j].Score
+	})
+	return options
+}
+
+// EffectiveOwnership estimates the percentage of managers who get a player's
+// points. Only the most captained player's captaincy is known, so every owner
+// of theirs is counted as captaining them and nobody else is.
+func EffectiveOwnership(player models.Player) float32 {
+	if player.MostCaptained {
+		return player.PickedPercentage * 2
+	}
+	return player.PickedPercentage
+}
+
+// pointsStdDev is the standard deviation of the player's points per gameweek
+// over their recent gameweeks.
+func pointsStdDev(player models.Player) float32 {
+	gameweeks := player.RecentGameweeks(varianceGameweeks)
+	if len(gameweeks) < 2 {
+		return 0
+	}
+	points := make([]float64, len(gameweeks))
+	var mean float64
+	for i, fixtures := range gameweeks {
+		for _, fixture := range fixtures {
+			points[i] += float64(fixture.Points)
+		}
+		mean += points[i]
+	}
+	mean /= float64(len(points))
+	var variance float64
+	for _, p := range points {
+		variance += (p - mean) * (p - mean)
+	}
+	variance /= float64(len(points) - 1)
+	return float32(math.Sqrt(variance))
+}
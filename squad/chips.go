@@ -0,0 +1,230 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Chip string
+
+const (
+	Wildcard      Chip = "wildcard"
+	FreeHit       Chip = "free-hit"
+	BenchBoost    Chip = "bench-boost"
+	TripleCaptain Chip = "triple-captain"
+)
+
+// Chips is every chip, in the order plans list them.
+var Chips = []Chip{Wildcard, FreeHit, BenchBoost, TripleCaptain}
+
+// ParseChips reads a comma separated list of chips, e.g. "free-hit,bench-boost".
+func ParseChips(chips string) ([]Chip, error) {
+	parsed := make([]Chip, 0)
+	for _, name := range strings.Split(chips, ",") {
+		chip := Chip(strings.TrimSpace(name))
+		if chip == "" {
+			continue
+		}
+		if !containsChip(Chips, chip) {
+			return nil, fmt.Errorf("unknown chip '%s' (expected wildcard, free-hit, bench-boost or triple-captain)", chip)
+		}
+		if !containsChip(parsed, chip) {
+			parsed = append(parsed, chip)
+		}
+	}
+	return parsed, nil
+}
+
+func containsChip(chips []Chip, chip Chip) bool {
+	for _, c := range chips {
+		if c == chip {
+			return true
+		}
+	}
+	return false
+}
+
+// ChipGameweek is what each chip would gain in a gameweek.
+type ChipGameweek struct {
+	GameweekID models.GameweekID
+	// Blanks and Doubles are the teams without a fixture and with more than
+	// one.
+	Blanks  []*models.Team
+	Doubles []*models.Team
+	// Points is the squad's best lineup's projected points without a chip.
+	Points float32
+	Gains  map[Chip]float32
+	// Play is the chip the plan plays in the gameweek, empty for none.
+	Play Chip
+}
+
+// ChipPlanner works out when to play each chip, comparing the squad's
+// projected points with what each chip adds.
+type ChipPlanner struct {
+	Squad       Squad
+	PlayerTypes []models.PlayerType
+	// Points is every player's projected points in each gameweek.
+	Points map[models.GameweekID]map[models.PlayerID]float32
+	// Fixtures is each team's fixture count in each gameweek.
+	Fixtures map[models.GameweekID]models.FixtureCounts
+	Teams    []*models.Team
+	// Budget is what a wildcard or free hit squad can cost, in £m.
+	Budget float32
+}
+
+func NewChipPlanner(squad Squad, playerTypes []models.PlayerType, points map[models.GameweekID]map[models.PlayerID]float32, budget float32) *ChipPlanner {
+	return &ChipPlanner{
+		Squad:       squad,
+		PlayerTypes: playerTypes,
+		Points:      points,
+		Fixtures:    make(map[models.GameweekID]models.FixtureCounts, 0),
+		Budget:      budget,
+	}
+}
+
+// Plan works out each chip's gain in every gameweek and plays each chip in
+// the gameweek it gains most, at most one chip a gameweek. Chips that gain
+// nothing aren't played.
+//
+// Every gain is for a single gameweek so the chips compare like for like. A
+// bench boost gains the bench's points and a triple captain the best
+// starter's. A free hit gains what the optimiser's squad for the gameweek
+// scores over the manager's. A wildcard gains what the optimiser's squad over
+// all the gameweeks scores over the manager's from that gameweek on, averaged
+// per gameweek.
+func (p *ChipPlanner) Plan(players []models.Player, gameweeks []models.GameweekID, chips []Chip) ([]ChipGameweek, error) {
+	sorted := make([]models.GameweekID, len(gameweeks))
+	copy(sorted, gameweeks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	var wildcardGains []float32
+	if containsChip(chips, Wildcard) {
+		var err error
+		wildcardGains, err = p.wildcardGains(players, sorted)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", Wildcard, err)
+		}
+	}
+
+	plan := make([]ChipGameweek, 0, len(sorted))
+	for n, gameweekID := range sorted {
+		points := p.Points[gameweekID]
+		lineup := PickLineup(p.Squad.Players, p.PlayerTypes, points)
+		gameweek := ChipGameweek{
+			GameweekID: gameweekID,
+			Blanks:     p.Fixtures[gameweekID].Blanks(p.Teams),
+			Doubles:    p.Fixtures[gameweekID].Doubles(p.Teams),
+			Points:     lineup.Points(points, 0),
+			Gains:      make(map[Chip]float32, len(chips)),
+		}
+		for _, chip := range chips {
+			var gain float32
+			var err error
+			switch chip {
+			case BenchBoost:
+				gain = lineup.Points(points, 1) - gameweek.Points
+			case TripleCaptain:
+				for _, player := range lineup.Starters {
+					if points[player.ID] > gain {
+						gain = points[player.ID]
+					}
+				}
+			case FreeHit:
+				gain, err = p.freeHitGain(players, points)
+			case Wildcard:
+				gain = wildcardGains[n]
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s in gameweek %d: %w", chip, gameweekID, err)
+			}
+			gameweek.Gains[chip] = gain
+		}
+		plan = append(plan, gameweek)
+	}
+
+	p.assignChips(plan, chips)
+	return plan, nil
+}
+
+// freeHitGain is how many more points the optimiser's squad for a gameweek's
+// points scores than the manager's.
+func (p *ChipPlanner) freeHitGain(players []models.Player, points map[models.PlayerID]float32) (float32, error) {
+	optimiser := NewOptimiser(points, p.PlayerTypes, p.Budget)
+	optimised, _, err := optimiser.Optimise(players)
+	if err != nil {
+		return 0, err
+	}
+	return max(0, p.lineupGain(optimised, points)), nil
+}
+
+// wildcardGains optimises a squad over all the gameweeks once, then works
+// out for each gameweek its average gain per gameweek over the manager's squad
+// from that gameweek to the last.
+func (p *ChipPlanner) wildcardGains(players []models.Player, gameweeks []models.GameweekID) ([]float32, error) {
+	optimiser := NewOptimiser(p.pointsOver(gameweeks), p.PlayerTypes, p.Budget)
+	optimised, _, err := optimiser.Optimise(players)
+	if err != nil {
+		return nil, err
+	}
+
+	gains := make([]float32, len(gameweeks))
+	var remaining float32
+	for n := len(gameweeks) - 1; n >= 0; n-- {
+		remaining += p.lineupGain(optimised, p.Points[gameweeks[n]])
+		gains[n] = max(0, remaining/float32(len(gameweeks)-n))
+	}
+	return gains, nil
+}
+
+// lineupGain is how many more points squad's best lineup scores than the
+// manager's.
+func (p *ChipPlanner) lineupGain(squad Squad, points map[models.PlayerID]float32) float32 {
+	ours := PickLineup(squad.Players, p.PlayerTypes, points)
+	current := PickLineup(p.Squad.Players, p.PlayerTypes, points)
+	return ours.Points(points, 0) - current.Points(points, 0)
+}
+
+// pointsOver adds up every player's points in the gameweeks.
+func (p *ChipPlanner) pointsOver(gameweeks []models.GameweekID) map[models.PlayerID]float32 {
+	total := make(map[models.PlayerID]float32, 0)
+	for _, gameweekID := range gameweeks {
+		for playerID, points := range p.Points[gameweekID] {
+			total[playerID] += points
+		}
+	}
+	return total
+}
+
+// assignChips plays the biggest gains first, skipping chips already played
+// and gameweeks that already have one.
+func (p *ChipPlanner) assignChips(plan []ChipGameweek, chips []Chip) {
+	type option struct {
+		gameweek int
+		chip     Chip
+		gain     float32
+	}
+	options := make([]option, 0, len(plan)*len(chips))
+	for n, gameweek := range plan {
+		for _, chip := range chips {
+			if gameweek.Gains[chip] > 0 {
+				options = append(options, option{n, chip, gameweek.Gains[chip]})
+			}
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].gain > options[j].gain
+	})
+
+	played := make(map[Chip]bool, len(chips))
+	for _, option := range options {
+		if played[option.chip] || plan[option.gameweek].Play != "" {
+			continue
+		}
+		plan[option.gameweek].Play = option.chip
+		played[option.chip] = true
+	}
+}
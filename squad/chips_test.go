@@ -0,0 +1,68 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"testing"
+)
+
+func TestChipPlannerPlaysBoostsInDoubles(t *testing.T) {
+	// every player scores 2 a fixture, twice in the doubles
+	gameweeks := []models.GameweekID{11, 12, 13, 14}
+	doubles := map[models.GameweekID]bool{12: true, 14: true}
+	players := lineupSquad(Size)
+	points := make(map[models.GameweekID]map[models.PlayerID]float32, len(gameweeks))
+	for _, gameweekID := range gameweeks {
+		points[gameweekID] = make(map[models.PlayerID]float32, len(players))
+		for _, player := range players {
+			points[gameweekID][player.ID] = 2
+			if doubles[gameweekID] {
+				points[gameweekID][player.ID] = 4
+			}
+		}
+	}
+
+	planner := NewChipPlanner(Squad{Players: players}, lineupPlayerTypes, points, DefaultBudget)
+	plan, err := planner.Plan(players, gameweeks, []Chip{BenchBoost, TripleCaptain})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	played := make(map[Chip]models.GameweekID, 0)
+	for _, gameweek := range plan {
+		if gameweek.Play == "" {
+			continue
+		}
+		if _, ok := played[gameweek.Play]; ok {
+			t.Errorf("%s played in gameweeks %d and %d", gameweek.Play, played[gameweek.Play], gameweek.GameweekID)
+		}
+		played[gameweek.Play] = gameweek.GameweekID
+	}
+	if played[BenchBoost] != 12 {
+		t.Errorf("bench boost played in gameweek %d, want the double in 12", played[BenchBoost])
+	}
+	if played[TripleCaptain] != 14 {
+		t.Errorf("triple captain played in gameweek %d, want the double in 14", played[TripleCaptain])
+	}
+	if gain := plan[1].Gains[BenchBoost]; gain != 16 {
+		t.Errorf("bench boost gains %.1f in gameweek 12, want the bench's 16", gain)
+	}
+}
+
+func TestAssignChips(t *testing.T) {
+	// the wildcard gains most in every gameweek but is only played once, and
+	// bench boost and triple captain gain nothing in the one gameweek left
+	// free, so aren't played
+	plan := []ChipGameweek{
+		{GameweekID: 1, Gains: map[Chip]float32{Wildcard: 10, FreeHit: 9, BenchBoost: 8, TripleCaptain: 7}},
+		{GameweekID: 2, Gains: map[Chip]float32{Wildcard: 6, FreeHit: 5, BenchBoost: 4, TripleCaptain: 3}},
+		{GameweekID: 3, Gains: map[Chip]float32{Wildcard: 2, FreeHit: 1}},
+	}
+	(&ChipPlanner{}).assignChips(plan, Chips)
+
+	want := []Chip{Wildcard, FreeHit, ""}
+	for n, gameweek := range plan {
+		if gameweek.Play != want[n] {
+			t.Errorf("gameweek %d plays %q, want %q", gameweek.GameweekID, gameweek.Play, want[n])
+		}
+	}
+}
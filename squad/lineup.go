@@ -0,0 +1,174 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"fmt"
+	"sort"
+)
+
+// Lineup is a squad split into its starting eleven and bench, the bench in
+// substitution order with the goalkeeper first.
+type Lineup struct {
+	Starters []models.Player
+	Bench    []models.Player
+}
+
+// StartingSize is the number of players in a starting eleven.
+const StartingSize = 11
+
+// PickLineup starts the players with the most points in a formation allowed by
+// each position's TeamMinPlayCount and TeamMaxPlayCount.
+func PickLineup(players []models.Player, playerTypes []models.PlayerType, points map[models.PlayerID]float32) Lineup {
+	ranked := make([]models.Player, len(players))
+	copy(ranked, players)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return points[ranked[i].ID] > points[ranked[j].ID]
+	})
+
+	minPlay := make(map[models.PlayerTypeID]int, len(playerTypes))
+	maxPlay := make(map[models.PlayerTypeID]int, len(playerTypes))
+	for _, playerType := range playerTypes {
+		minPlay[playerType.ID] = playerType.TeamMinPlayCount
+		maxPlay[playerType.ID] = playerType.TeamMaxPlayCount
+	}
+
+	starting := make(map[models.PlayerID]bool, StartingSize)
+	playing := make(map[models.PlayerTypeID]int, len(playerTypes))
+	// each position's minimum first, then the best of the rest
+	for _, player := range ranked {
+		if playing[player.Type.ID] < minPlay[player.Type.ID] {
+			starting[player.ID] = true
+			playing[player.Type.ID]++
+		}
+	}
+	for _, player := range ranked {
+		if len(starting) == StartingSize {
+			break
+		}
+		if !starting[player.ID] && playing[player.Type.ID] < maxPlay[player.Type.ID] {
+			starting[player.ID] = true
+			playing[player.Type.ID]++
+		}
+	}
+
+	lineup := Lineup{
+		Starters: make([]models.Player, 0, StartingSize),
+		Bench:    make([]models.Player, 0, max(0, len(players)-StartingSize)),
+	}
+	for _, player := range ranked {
+		if starting[player.ID] {
+			lineup.Starters = append(lineup.Starters, player)
+		} else {
+			lineup.Bench = append(lineup.Bench, player)
+		}
+	}
+	sort.SliceStable(lineup.Starters, func(i, j int) bool {
+		return lineup.Starters[i].Type.ID < lineup.Starters[j].Type.ID
+	})
+	sort.SliceStable(lineup.Bench, func(i, j int) bool {
+		return lineup.Bench[i].Type.ID == models.PTGoalkeeper && lineup.Bench[j].Type.ID != models.PTGoalkeeper
+	})
+	return lineup
+}
+
+// Points adds up the starters' points and benchWeight of the bench's.
+func (l Lineup) Points(points map[models.PlayerID]float32, benchWeight float32) float32 {
+	var total float32
+	for _, player := range l.Starters {
+		total += points[player.ID]
+	}
+	for _, player := range l.Bench {
+		total += benchWeight * points[player.ID]
+	}
+	return total
+}
+
+// OrderBench puts the goalkeeper first, then the outfield bench by expected
+// autosub value: their points times the chance at least one starter they
+// could legally replace doesn't play. chances are each player's chance of
+// playing at all.
+func (l Lineup) OrderBench(playerTypes []models.PlayerType, points map[models.PlayerID]float32, chances map[models.PlayerID]float32) Lineup {
+	values := make(map[models.PlayerID]float32, len(l.Bench))
+	for _, sub := range l.Bench {
+		values[sub.ID] = l.AutosubValue(sub, playerTypes, points, chances)
+	}
+
+	bench := make([]models.Player, len(l.Bench))
+	copy(bench, l.Bench)
+	sort.SliceStable(bench, func(i, j int) bool {
+		iKeeper, jKeeper := bench[i].Type.ID == models.PTGoalkeeper, bench[j].Type.ID == models.PTGoalkeeper
+		if iKeeper != jKeeper {
+			return iKeeper
+		}
+		return values[bench[i].ID] > values[bench[j].ID]
+	})
+	l.Bench = bench
+	return l
+}
+
+// AutosubValue is the points a bench player is expected to add by coming on
+// for a starter who doesn't play.
+func (l Lineup) AutosubValue(sub models.Player, playerTypes []models.PlayerType, points map[models.PlayerID]float32, chances map[models.PlayerID]float32) float32 {
+	minPlay := make(map[models.PlayerTypeID]int, len(playerTypes))
+	maxPlay := make(map[models.PlayerTypeID]int, len(playerTypes))
+	for _, playerType := range playerTypes {
+		minPlay[playerType.ID] = playerType.TeamMinPlayCount
+		maxPlay[playerType.ID] = playerType.TeamMaxPlayCount
+	}
+	playing := make(map[models.PlayerTypeID]int, len(playerTypes))
+	for _, starter := range l.Starters {
+		playing[starter.Type.ID]++
+	}
+
+	allPlay := float32(1)
+	for _, starter := range l.Starters {
+		replaceable := starter.Type.ID == sub.Type.ID ||
+			(starter.Type.ID != models.PTGoalkeeper && sub.Type.ID != models.PTGoalkeeper &&
+				playing[starter.Type.ID]-1 >= minPlay[starter.Type.ID] &&
+				playing[sub.Type.ID]+1 <= maxPlay[sub.Type.ID])
+		if !replaceable {
+			continue
+		}
+		chance, ok := chances[starter.ID]
+		if !ok {
+			chance = 1
+		}
+		allPlay *= chance
+	}
+	return points[sub.ID] * (1 - allPlay)
+}
+
+// Formation is the number of starting defenders, midfielders and forwards,
+// e.g. "4-4-2".
+func (l Lineup) Formation() string {
+	playing := make(map[models.PlayerTypeID]int, 0)
+	for _, starter := range l.Starters {
+		playing[starter.Type.ID]++
+	}
+	return fmt.Sprintf("%d-%d-%d", playing[models.PTDefender], playing[models.PTMidfielder], playing[models.PTForward])
+}
+
+// StartingEleven groups the starters by position name, along with their next
+// fixture and their ranks by points overall and within their position.
+func (l Lineup) StartingEleven(nextFixtures map[models.TeamID]*models.Fixture, overallRanks map[models.PlayerID]int, typeRanks map[models.PlayerID]int) models.StartingEleven {
+	eleven := make(models.StartingEleven, 0)
+	for _, starter := range l.Starters {
+		startingPlayer := models.StartingPlayer{
+			Player:      starter,
+			OverallRank: fmt.Sprint(overallRanks[starter.ID]),
+			TypeRank:    fmt.Sprint(typeRanks[starter.ID]),
+		}
+		if starter.Team != nil {
+			if fixture, ok := nextFixtures[starter.Team.ID]; ok {
+				startingPlayer.Fixture = *fixture
+				if fixture.HomeTeam.ID == starter.Team.ID {
+					startingPlayer.OpposingTeam = *fixture.AwayTeam
+				} else {
+					startingPlayer.OpposingTeam = *fixture.HomeTeam
+				}
+			}
+		}
+		eleven[starter.Type.Name] = append(eleven[starter.Type.Name], startingPlayer)
+	}
+	return eleven
+}
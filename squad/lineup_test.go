@@ -0,0 +1,109 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"reflect"
+	"testing"
+)
+
+var lineupPlayerTypes = []models.PlayerType{
+	{ID: models.PTGoalkeeper, TeamMinPlayCount: 1, TeamMaxPlayCount: 1},
+	{ID: models.PTDefender, TeamMinPlayCount: 3, TeamMaxPlayCount: 5},
+	{ID: models.PTMidfielder, TeamMinPlayCount: 2, TeamMaxPlayCount: 5},
+	{ID: models.PTForward, TeamMinPlayCount: 1, TeamMaxPlayCount: 3},
+}
+
+// lineupSquad is two goalkeepers (1-2), five defenders (3-7), five
+// midfielders (8-12) and three forwards (13-15), or the first size of them.
+func lineupSquad(size int) []models.Player {
+	players := make([]models.Player, 0, size)
+	for id := 1; id <= size; id++ {
+		playerType := models.PTForward
+		switch {
+		case id <= 2:
+			playerType = models.PTGoalkeeper
+		case id <= 7:
+			playerType = models.PTDefender
+		case id <= 12:
+			playerType = models.PTMidfielder
+		}
+		players = append(players, models.Player{ID: models.PlayerID(id), Type: models.PlayerType{ID: playerType}})
+	}
+	return players
+}
+
+func TestPickLineup(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		points    map[models.PlayerID]float32
+		starters  []models.PlayerID
+		bench     []models.PlayerID
+		formation string
+	}{
+		{
+			name: "minimums start before better players",
+			size: 15,
+			points: map[models.PlayerID]float32{
+				1: 5, 2: 9,
+				3: 1, 4: 1.5, 5: 3, 6: 2.5, 7: 0.5,
+				8: 4, 9: 6, 10: 2.8, 11: 7, 12: 3.5,
+				13: 10, 14: 8, 15: 0.1,
+			},
+			starters:  []models.PlayerID{2, 5, 6, 4, 11, 9, 8, 12, 10, 13, 14},
+			bench:     []models.PlayerID{1, 3, 7, 15},
+			formation: "3-5-2",
+		},
+		{
+			name: "maximums leave better players on the bench",
+			size: 15,
+			points: map[models.PlayerID]float32{
+				1: 9, 2: 8,
+				3: 20, 4: 21, 5: 22, 6: 23, 7: 24,
+				8: 2, 9: 3, 10: 4, 11: 1, 12: 0.5,
+				13: 0.1, 14: 0.2, 15: 0.3,
+			},
+			starters:  []models.PlayerID{1, 7, 6, 5, 4, 3, 10, 9, 8, 11, 15},
+			bench:     []models.PlayerID{2, 12, 14, 13},
+			formation: "5-4-1",
+		},
+		{
+			name:      "goalkeeper leads the bench with fewer points",
+			size:      15,
+			points:    map[models.PlayerID]float32{1: 5, 2: 0, 3: 4, 4: 4, 5: 4, 6: 4, 7: 4, 8: 4, 9: 4, 10: 4, 11: 4, 12: 4, 13: 4, 14: 4, 15: 4},
+			starters:  []models.PlayerID{1, 3, 4, 5, 6, 7, 8, 9, 10, 11, 13},
+			bench:     []models.PlayerID{2, 12, 14, 15},
+			formation: "5-4-1",
+		},
+		{
+			name:      "short squad starts all it can",
+			size:      8,
+			points:    map[models.PlayerID]float32{},
+			starters:  []models.PlayerID{1, 3, 4, 5, 6, 7, 8},
+			bench:     []models.PlayerID{2},
+			formation: "5-1-0",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lineup := PickLineup(lineupSquad(test.size), lineupPlayerTypes, test.points)
+			if got := playerIDs(lineup.Starters); !reflect.DeepEqual(got, test.starters) {
+				t.Errorf("starters = %v, want %v", got, test.starters)
+			}
+			if got := playerIDs(lineup.Bench); !reflect.DeepEqual(got, test.bench) {
+				t.Errorf("bench = %v, want %v", got, test.bench)
+			}
+			if got := lineup.Formation(); got != test.formation {
+				t.Errorf("formation = %s, want %s", got, test.formation)
+			}
+		})
+	}
+}
+
+func playerIDs(players []models.Player) []models.PlayerID {
+	ids := make([]models.PlayerID, 0, len(players))
+	for _, player := range players {
+		ids = append(ids, player.ID)
+	}
+	return ids
+}
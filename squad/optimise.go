@@ -0,0 +1,302 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"errors"
+	"sort"
+)
+
+const (
+	// DefaultBudget is the budget of a new squad, in £m.
+	DefaultBudget = 100
+	// DefaultBenchWeight is how much a bench player's points count for,
+	// since they only play when a starter doesn't.
+	DefaultBenchWeight = 0.1
+
+	// optimiserCandidates is how many of each position's best projected
+	// players the optimiser considers, along with the cheapest.
+	optimiserCandidates = 50
+	cheapCandidates     = 10
+
+	// maxPlayerTypes bounds the player type IDs score handles without
+	// allocating.
+	maxPlayerTypes = 8
+)
+
+var ErrOverBudget = errors.New("no squad fits the budget")
+
+// Optimiser builds a squad from scratch for wildcards and free hits, searching
+// for the most projected points with a heuristic rather than proving the best.
+type Optimiser struct {
+	Projections map[models.PlayerID]float32
+	PlayerTypes []models.PlayerType
+	Budget      float32
+	BenchWeight float32
+}
+
+func NewOptimiser(projections map[models.PlayerID]float32, playerTypes []models.PlayerType, budget float32) *Optimiser {
+	return &Optimiser{
+		Projections: projections,
+		PlayerTypes: playerTypes,
+		Budget:      budget,
+		BenchWeight: DefaultBenchWeight,
+	}
+}
+
+// Optimise starts from the cheapest legal squad and keeps making whichever
+// one or two player swap improves the lineup's projected points most, until
+// none does. It's a hill climb over each position's best and cheapest
+// players, so it can stop at a squad no swap improves that still isn't the
+// best possible.
+func (o *Optimiser) Optimise(players []models.Player) (Squad, Lineup, error) {
+	candidates := o.candidates(players)
+
+	current, err := o.cheapestSquad(candidates)
+	if err != nil {
+		return Squad{}, Lineup{}, err
+	}
+	score := o.score(current)
+
+	for {
+		improved, improvedScore := o.bestSwap(current, candidates, score, 1)
+		if improved == nil {
+			improved, improvedScore = o.bestSwap(current, candidates, score, 2)
+		}
+		if improved == nil {
+			break
+		}
+		current, score = improved, improvedScore
+	}
+
+	squad := Squad{Players: current, Bank: o.Budget - Squad{Players: current}.Value()}
+	return squad, PickLineup(current, o.PlayerTypes, o.Projections), nil
+}
+
+// score is the points of the squad's best lineup, the same as PickLineup's
+// but without allocating, since the search scores a lot of squads.
+func (o *Optimiser) score(players []models.Player) float32 {
+	var minPlay, maxPlay [maxPlayerTypes]int
+	for _, playerType := range o.PlayerTypes {
+		if int(playerType.ID) >= maxPlayerTypes {
+			return PickLineup(players, o.PlayerTypes, o.Projections).Points(o.Projections, o.BenchWeight)
+		}
+		minPlay[playerType.ID] = playerType.TeamMinPlayCount
+		maxPlay[playerType.ID] = playerType.TeamMaxPlayCount
+	}
+
+	// each position's points, best first
+	var points [maxPlayerTypes][Size]float32
+	var counts [maxPlayerTypes]int
+	for _, player := range players {
+		position := player.Type.ID
+		if int(position) >= maxPlayerTypes || counts[position] == Size {
+			return PickLineup(players, o.PlayerTypes, o.Projections).Points(o.Projections, o.BenchWeight)
+		}
+		p := o.Projections[player.ID]
+		k := counts[position]
+		for k > 0 && points[position][k-1] < p {
+			points[position][k] = points[position][k-1]
+			k--
+		}
+		points[position][k] = p
+		counts[position]++
+	}
+
+	var total float32
+	var playing [maxPlayerTypes]int
+	starters := 0
+	for position := range playing {
+		for playing[position] < minPlay[position] && playing[position] < counts[position] {
+			total += points[position][playing[position]]
+			playing[position]++
+			starters++
+		}
+	}
+	// then whichever position's next best player has the most points
+	for starters < StartingSize {
+		best := -1
+		for position := range playing {
+			if playing[position] < counts[position] && playing[position] < maxPlay[position] &&
+				(best < 0 || points[position][playing[position]] > points[best][playing[best]]) {
+				best = position
+			}
+		}
+		if best < 0 {
+			break
+		}
+		total += points[best][playing[best]]
+		playing[best]++
+		starters++
+	}
+	for position := range playing {
+		for k := playing[position]; k < counts[position]; k++ {
+			total += o.BenchWeight * points[position][k]
+		}
+	}
+	return total
+}
+
+// bestSwap tries replacing every one (or two) squad players with candidates
+// of the same position, returning the best squad that beats score, or nil.
+// Each swap is made and scored in place on one working copy of the squad, so
+// the search doesn't allocate; only the best swap is built into a new squad.
+func (o *Optimiser) bestSwap(players []models.Player, candidates map[models.PlayerTypeID][]models.Player, score float32, swaps int) ([]models.Player, float32) {
+	spare := tenths(o.Budget) - tenths(Squad{Players: players}.Value())
+	teams := Squad{Players: players}.teamCounts()
+	inSquad := make(map[models.PlayerID]bool, len(players))
+	for _, player := range players {
+		inSquad[player.ID] = true
+	}
+
+	working := make([]models.Player, len(players))
+	copy(working, players)
+	bestScore := score
+	// the best swap's squad positions and incoming players, second unused (-1)
+	// for a single swap
+	bestFirst, bestSecond := -1, -1
+	var bestFirstIn, bestSecondIn models.Player
+
+	for i, firstOut := range players {
+		for _, firstIn := range candidates[firstOut.Type.ID] {
+			if inSquad[firstIn.ID] {
+				continue
+			}
+			firstCost := tenths(firstIn.RawCost) - tenths(firstOut.RawCost)
+			if swaps == 1 {
+				if firstCost > spare || !swapFitsTeams(teams, firstOut, firstIn, nil, nil) {
+					continue
+				}
+				working[i] = firstIn
+				// ignore rounding noise so the search always ends
+				if s := o.score(working); s > bestScore+0.001 {
+					bestScore, bestFirst, bestFirstIn = s, i, firstIn
+				}
+				working[i] = firstOut
+				continue
+			}
+			for j := i + 1; j < len(players); j++ {
+				secondOut := players[j]
+				for _, secondIn := range candidates[secondOut.Type.ID] {
+					if secondIn.ID == firstIn.ID || inSquad[secondIn.ID] {
+						continue
+					}
+					if firstCost+tenths(secondIn.RawCost)-tenths(secondOut.RawCost) > spare ||
+						!swapFitsTeams(teams, firstOut, firstIn, &secondOut, &secondIn) {
+						continue
+					}
+					working[i], working[j] = firstIn, secondIn
+					if s := o.score(working); s > bestScore+0.001 {
+						bestScore, bestFirst, bestFirstIn, bestSecond, bestSecondIn = s, i, firstIn, j, secondIn
+					}
+					working[i], working[j] = firstOut, secondOut
+				}
+			}
+		}
+	}
+
+	if bestFirst < 0 {
+		return nil, score
+	}
+	best := make([]models.Player, len(players))
+	copy(best, players)
+	best[bestFirst] = bestFirstIn
+	if bestSecond >= 0 {
+		best[bestSecond] = bestSecondIn
+	}
+	return best, bestScore
+}
+
+// swapFitsTeams is fitsTeams for one swap, or two when secondOut and secondIn
+// are set, without allocating.
+func swapFitsTeams(teams map[models.TeamID]int, firstOut models.Player, firstIn models.Player, secondOut *models.Player, secondIn *models.Player) bool {
+	outs := []*models.Team{firstOut.Team, nil}
+	ins := []*models.Team{firstIn.Team, nil}
+	if secondOut != nil && secondIn != nil {
+		outs[1], ins[1] = secondOut.Team, secondIn.Team
+	}
+	for _, in := range ins {
+		if in == nil {
+			continue
+		}
+		count := teams[in.ID]
+		for _, team := range ins {
+			if team != nil && team.ID == in.ID {
+				count++
+			}
+		}
+		for _, team := range outs {
+			if team != nil && team.ID == in.ID {
+				count--
+			}
+		}
+		if count > MaxPerTeam {
+			return false
+		}
+	}
+	return true
+}
+
+// cheapestSquad fills each position's quota with the cheapest players,
+// keeping to MaxPerTeam.
+func (o *Optimiser) cheapestSquad(candidates map[models.PlayerTypeID][]models.Player) ([]models.Player, error) {
+	players := make([]models.Player, 0, Size)
+	teams := make(map[models.TeamID]int, 0)
+	for _, playerType := range o.PlayerTypes {
+		cheapest := make([]models.Player, len(candidates[playerType.ID]))
+		copy(cheapest, candidates[playerType.ID])
+		sort.SliceStable(cheapest, func(i, j int) bool {
+			return cheapest[i].RawCost < cheapest[j].RawCost
+		})
+		picked := 0
+		for _, player := range cheapest {
+			if picked == playerType.TeamPlayerCount {
+				break
+			}
+			if player.Team != nil && teams[player.Team.ID] >= MaxPerTeam {
+				continue
+			}
+			if player.Team != nil {
+				teams[player.Team.ID]++
+			}
+			players = append(players, player)
+			picked++
+		}
+		if picked < playerType.TeamPlayerCount {
+			return nil, errors.New("not enough players to fill " + playerType.PluralName)
+		}
+	}
+	if (Squad{Players: players}).Value() > o.Budget {
+		return nil, ErrOverBudget
+	}
+	return players, nil
+}
+
+// candidates are each position's best projected players and its cheapest.
+func (o *Optimiser) candidates(players []models.Player) map[models.PlayerTypeID][]models.Player {
+	byPosition := make(map[models.PlayerTypeID][]models.Player, 0)
+	for _, player := range players {
+		byPosition[player.Type.ID] = append(byPosition[player.Type.ID], player)
+	}
+
+	candidates := make(map[models.PlayerTypeID][]models.Player, len(byPosition))
+	for position, players := range byPosition {
+		sort.SliceStable(players, func(i, j int) bool {
+			return o.Projections[players[i].ID] > o.Projections[players[j].ID]
+		})
+		included := make(map[models.PlayerID]bool, 0)
+		for _, player := range players[:min(len(players), optimiserCandidates)] {
+			candidates[position] = append(candidates[position], player)
+			included[player.ID] = true
+		}
+
+		sort.SliceStable(players, func(i, j int) bool {
+			return players[i].RawCost < players[j].RawCost
+		})
+		for _, player := range players[:min(len(players), cheapCandidates)] {
+			if !included[player.ID] {
+				candidates[position] = append(candidates[position], player)
+			}
+		}
+	}
+	return candidates
+}
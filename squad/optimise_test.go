@@ -0,0 +1,104 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"errors"
+	"testing"
+)
+
+var squadPlayerTypes = []models.PlayerType{
+	{ID: models.PTGoalkeeper, PluralName: "Goalkeepers", TeamPlayerCount: 2, TeamMinPlayCount: 1, TeamMaxPlayCount: 1},
+	{ID: models.PTDefender, PluralName: "Defenders", TeamPlayerCount: 5, TeamMinPlayCount: 3, TeamMaxPlayCount: 5},
+	{ID: models.PTMidfielder, PluralName: "Midfielders", TeamPlayerCount: 5, TeamMinPlayCount: 2, TeamMaxPlayCount: 5},
+	{ID: models.PTForward, PluralName: "Forwards", TeamPlayerCount: 3, TeamMinPlayCount: 1, TeamMaxPlayCount: 3},
+}
+
+// playerPool is ten players of each position. The first three of each are
+// £9.0m stars from team 1 projected 20 points, the rest are from teams 4-10,
+// costing £4.0m up in £0.5m steps and projected as many points as their
+// place in the position.
+func playerPool() ([]models.Player, map[models.PlayerID]float32) {
+	players := make([]models.Player, 0)
+	points := make(map[models.PlayerID]float32, 0)
+	teams := make(map[models.TeamID]*models.Team, 0)
+	for _, playerType := range squadPlayerTypes {
+		for k := 0; k < 10; k++ {
+			id := models.PlayerID(len(players) + 1)
+			teamID := models.TeamID(k + 1)
+			cost := 4 + 0.5*float32(k-3)
+			points[id] = float32(k)
+			if k < 3 {
+				teamID, cost, points[id] = 1, 9, 20
+			}
+			if teams[teamID] == nil {
+				teams[teamID] = &models.Team{ID: teamID}
+			}
+			players = append(players, models.Player{ID: id, Team: teams[teamID], Type: playerType, RawCost: cost})
+		}
+	}
+	return players, points
+}
+
+func TestOptimise(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget float32
+	}{
+		{name: "room for every star", budget: 200},
+		{name: "default budget", budget: DefaultBudget},
+		{name: "tight budget", budget: 80},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			players, points := playerPool()
+			optimised, lineup, err := NewOptimiser(points, squadPlayerTypes, test.budget).Optimise(players)
+			if err != nil {
+				t.Fatalf("Optimise() error = %v", err)
+			}
+			if err := optimised.Validate(squadPlayerTypes); err != nil {
+				t.Errorf("Optimise() squad isn't valid: %v", err)
+			}
+			if value := optimised.Value(); value > test.budget {
+				t.Errorf("squad costs £%.1fm, over the £%.1fm budget", value, test.budget)
+			}
+			if tenths(optimised.Bank) != tenths(test.budget)-tenths(optimised.Value()) {
+				t.Errorf("bank = £%.1fm, want the £%.1fm budget less the squad's £%.1fm", optimised.Bank, test.budget, optimised.Value())
+			}
+
+			positions := make(map[models.PlayerTypeID]int, 0)
+			for _, player := range optimised.Players {
+				positions[player.Type.ID]++
+			}
+			for _, playerType := range squadPlayerTypes {
+				if positions[playerType.ID] != playerType.TeamPlayerCount {
+					t.Errorf("%d %s, want %d", positions[playerType.ID], playerType.PluralName, playerType.TeamPlayerCount)
+				}
+			}
+			if stars := optimised.teamCounts()[1]; stars > MaxPerTeam {
+				t.Errorf("%d players from team 1, want at most %d", stars, MaxPerTeam)
+			}
+			if len(lineup.Starters) != StartingSize {
+				t.Errorf("%d starters, want %d", len(lineup.Starters), StartingSize)
+			}
+		})
+	}
+}
+
+func TestOptimiseTakesStarsUpToTheTeamLimit(t *testing.T) {
+	players, points := playerPool()
+	optimised, _, err := NewOptimiser(points, squadPlayerTypes, 200).Optimise(players)
+	if err != nil {
+		t.Fatalf("Optimise() error = %v", err)
+	}
+	if stars := optimised.teamCounts()[1]; stars != MaxPerTeam {
+		t.Errorf("%d players from team 1, want %d", stars, MaxPerTeam)
+	}
+}
+
+func TestOptimiseOverBudget(t *testing.T) {
+	players, points := playerPool()
+	_, _, err := NewOptimiser(points, squadPlayerTypes, 50).Optimise(players)
+	if !errors.Is(err, ErrOverBudget) {
+		t.Errorf("Optimise() error = %v, want %v", err, ErrOverBudget)
+	}
+}
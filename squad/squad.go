@@ -0,0 +1,107 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"fmt"
+	"math"
+)
+
+const (
+	// MaxPerTeam is the most players a squad can have from one club.
+	MaxPerTeam = 3
+	// Size is the number of players in a full squad.
+	Size = 15
+)
+
+// Squad is a manager's fifteen players and the money they have left.
+type Squad struct {
+	Players []models.Player
+	Bank    float32
+}
+
+// FromPicks builds a manager's squad from their picks.
+func FromPicks(picks []models.ManagerPick, players []models.Player, bank float32) (Squad, error) {
+	playersByID := make(map[models.PlayerID]models.Player, len(players))
+	for _, player := range players {
+		playersByID[player.ID] = player
+	}
+
+	squad := Squad{
+		Players: make([]models.Player, 0, len(picks)),
+		Bank:    bank,
+	}
+	for _, pick := range picks {
+		player, ok := playersByID[models.PlayerID(pick.PlayerID)]
+		if !ok {
+			return Squad{}, fmt.Errorf("missing picked player ID '%d'", pick.PlayerID)
+		}
+		squad.Players = append(squad.Players, player)
+	}
+	return squad, nil
+}
+
+// tenths converts £m to the whole tenths of a million the api prices in, so
+// money adds up without float rounding.
+func tenths(millions float32) int {
+	return int(math.Round(float64(millions) * 10))
+}
+
+// millions converts tenths of a million back to £m.
+func millions(tenths int) float32 {
+	return float32(tenths) / 10
+}
+
+// Value is the cost of every player in the squad.
+func (s Squad) Value() float32 {
+	var value float32
+	for _, player := range s.Players {
+		value += player.RawCost
+	}
+	return value
+}
+
+func (s Squad) Contains(playerID models.PlayerID) bool {
+	for _, player := range s.Players {
+		if player.ID == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the squad has each position's quota of players, no more
+// than MaxPerTeam from a club and isn't overdrawn.
+func (s Squad) Validate(playerTypes []models.PlayerType) error {
+	if s.Bank < 0 {
+		return fmt.Errorf("over budget by £%.1fm", -s.Bank)
+	}
+
+	positions := make(map[models.PlayerTypeID]int, 0)
+	teams := make(map[models.TeamID]int, 0)
+	for _, player := range s.Players {
+		positions[player.Type.ID]++
+		if player.Team != nil {
+			teams[player.Team.ID]++
+			if teams[player.Team.ID] > MaxPerTeam {
+				return fmt.Errorf("more than %d players from %s", MaxPerTeam, player.Team.Name)
+			}
+		}
+	}
+	for _, playerType := range playerTypes {
+		if positions[playerType.ID] != playerType.TeamPlayerCount {
+			return fmt.Errorf("%d %s, needs %d", positions[playerType.ID], playerType.PluralName, playerType.TeamPlayerCount)
+		}
+	}
+	return nil
+}
+
+// teamCounts counts the squad's players from each club.
+func (s Squad) teamCounts() map[models.TeamID]int {
+	teams := make(map[models.TeamID]int, 0)
+	for _, player := range s.Players {
+		if player.Team != nil {
+			teams[player.Team.ID]++
+		}
+	}
+	return teams
+}
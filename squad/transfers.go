@@ -0,0 +1,189 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"sort"
+)
+
+const (
+	// HitCost is the points deducted for each transfer beyond the free ones.
+	HitCost = 4
+	// DefaultFreeTransfers is how many free transfers a manager usually has.
+	DefaultFreeTransfers = 1
+	// DefaultMaxTransfers is the most transfers a plan makes.
+	DefaultMaxTransfers = 2
+
+	// candidatesPerPosition is how many of the best projected affordable
+	// players of each position are considered for a transfer in.
+	candidatesPerPosition = 40
+)
+
+type Transfer struct {
+	Out models.Player
+	In  models.Player
+}
+
+// Plan is a set of transfers and what they're projected to gain.
+type Plan struct {
+	Transfers []Transfer
+	// Hits is the points deducted for transfers beyond the free ones.
+	Hits int
+	// Gain is the change in the squad's projected points, less hits.
+	Gain float32
+	Bank float32
+}
+
+// Planner suggests transfers by the projected points of the players going in
+// and out. Selling prices are taken to be current costs, since the api
+// doesn't give them without logging in.
+type Planner struct {
+	// Projections are each player's projected points over the horizon.
+	Projections   map[models.PlayerID]float32
+	FreeTransfers int
+	MaxTransfers  int
+}
+
+func NewPlanner(projections map[models.PlayerID]float32, freeTransfers int) *Planner {
+	return &Planner{
+		Projections:   projections,
+		FreeTransfers: freeTransfers,
+		MaxTransfers:  DefaultMaxTransfers,
+	}
+}
+
+// Plan returns the best plan for each number of transfers from 0 to
+// MaxTransfers, best gain first. Numbers of transfers with no legal plan are
+// left out.
+func (p *Planner) Plan(squad Squad, players []models.Player) []Plan {
+	teams := squad.teamCounts()
+
+	plans := []Plan{{Transfers: make([]Transfer, 0), Bank: squad.Bank}}
+	if p.MaxTransfers >= 1 {
+		candidates := p.candidates(squad, players, 0)
+		if plan, ok := p.bestSingle(squad, candidates, teams); ok {
+			plans = append(plans, plan)
+		}
+	}
+	if p.MaxTransfers >= 2 {
+		// the other sale can pay towards either player coming in
+		var mostExpensive int
+		for _, player := range squad.Players {
+			mostExpensive = max(mostExpensive, tenths(player.RawCost))
+		}
+		candidates := p.candidates(squad, players, mostExpensive)
+		if plan, ok := p.bestDouble(squad, candidates, teams); ok {
+			plans = append(plans, plan)
+		}
+	}
+
+	sort.SliceStable(plans, func(i, j int) bool {
+		return plans[i].Gain > plans[j].Gain
+	})
+	return plans
+}
+
+func (p *Planner) bestSingle(squad Squad, candidates map[models.PlayerTypeID][]models.Player, teams map[models.TeamID]int) (Plan, bool) {
+	var best Plan
+	found := false
+	for _, out := range squad.Players {
+		for _, in := range candidates[out.Type.ID] {
+			bank := tenths(squad.Bank) + tenths(out.RawCost) - tenths(in.RawCost)
+			if bank < 0 || !fitsTeams(teams, []Transfer{{out, in}}) {
+				continue
+			}
+			plan := p.newPlan([]Transfer{{out, in}}, bank)
+			if !found || plan.Gain > best.Gain {
+				best, found = plan, true
+			}
+		}
+	}
+	return best, found
+}
+
+func (p *Planner) bestDouble(squad Squad, candidates map[models.PlayerTypeID][]models.Player, teams map[models.TeamID]int) (Plan, bool) {
+	var best Plan
+	found := false
+	for i, firstOut := range squad.Players {
+		for _, secondOut := range squad.Players[i+1:] {
+			for _, firstIn := range candidates[firstOut.Type.ID] {
+				for _, secondIn := range candidates[secondOut.Type.ID] {
+					if firstIn.ID == secondIn.ID {
+						continue
+					}
+					bank := tenths(squad.Bank) + tenths(firstOut.RawCost) + tenths(secondOut.RawCost) - tenths(firstIn.RawCost) - tenths(secondIn.RawCost)
+					transfers := []Transfer{{firstOut, firstIn}, {secondOut, secondIn}}
+					if bank < 0 || !fitsTeams(teams, transfers) {
+						continue
+					}
+					plan := p.newPlan(transfers, bank)
+					if !found || plan.Gain > best.Gain {
+						best, found = plan, true
+					}
+				}
+			}
+		}
+	}
+	return best, found
+}
+
+// newPlan makes a plan leaving bank tenths of a million in the bank.
+func (p *Planner) newPlan(transfers []Transfer, bank int) Plan {
+	plan := Plan{
+		Transfers: transfers,
+		Hits:      max(0, len(transfers)-p.FreeTransfers) * HitCost,
+		Bank:      millions(bank),
+	}
+	for _, transfer := range transfers {
+		plan.Gain += p.Projections[transfer.In.ID] - p.Projections[transfer.Out.ID]
+	}
+	plan.Gain -= float32(plan.Hits)
+	return plan
+}
+
+// candidates are the best projected players of each position who aren't in
+// the squad and could be afforded by selling the squad's most expensive
+// player of that position, plus extra tenths of a million from other sales.
+func (p *Planner) candidates(squad Squad, players []models.Player, extra int) map[models.PlayerTypeID][]models.Player {
+	budgets := make(map[models.PlayerTypeID]int, 0)
+	for _, player := range squad.Players {
+		budgets[player.Type.ID] = max(budgets[player.Type.ID], tenths(player.RawCost)+tenths(squad.Bank)+extra)
+	}
+
+	candidates := make(map[models.PlayerTypeID][]models.Player, 0)
+	for _, player := range players {
+		budget, ok := budgets[player.Type.ID]
+		if !ok || tenths(player.RawCost) > budget || squad.Contains(player.ID) {
+			continue
+		}
+		candidates[player.Type.ID] = append(candidates[player.Type.ID], player)
+	}
+	for position, players := range candidates {
+		sort.SliceStable(players, func(i, j int) bool {
+			return p.Projections[players[i].ID] > p.Projections[players[j].ID]
+		})
+		if len(players) > candidatesPerPosition {
+			candidates[position] = players[:candidatesPerPosition]
+		}
+	}
+	return candidates
+}
+
+// fitsTeams reports whether making the transfers keeps the squad within
+// MaxPerTeam players of each club.
+func fitsTeams(teams map[models.TeamID]int, transfers []Transfer) bool {
+	changes := make(map[models.TeamID]int, 0)
+	for _, transfer := range transfers {
+		if transfer.Out.Team != nil {
+			changes[transfer.Out.Team.ID]--
+		}
+		if transfer.In.Team != nil {
+			changes[transfer.In.Team.ID]++
+		}
+	}
+	for teamID, change := range changes {
+		if teams[teamID]+change > MaxPerTeam {
+			return false
+		}
+	}
+	return true
+}
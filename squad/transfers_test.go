@@ -0,0 +1,156 @@
+package squad
+
+import (
+	"better-fantasy/models"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// transferPlayer is a player of a position from a team, costing cost £m.
+func transferPlayer(id models.PlayerID, position models.PlayerTypeID, teamID models.TeamID, cost float32) models.Player {
+	return models.Player{ID: id, Type: models.PlayerType{ID: position}, Team: &models.Team{ID: teamID}, RawCost: cost}
+}
+
+var (
+	midfielderA = transferPlayer(1, models.PTMidfielder, 1, 5)
+	midfielderB = transferPlayer(2, models.PTMidfielder, 2, 5)
+	forwardB    = transferPlayer(3, models.PTForward, 2, 5)
+	defender1   = transferPlayer(4, models.PTDefender, 9, 4)
+	defender2   = transferPlayer(5, models.PTDefender, 9, 4)
+	defender3   = transferPlayer(6, models.PTDefender, 9, 4)
+
+	// players to bring in
+	midfielderX = transferPlayer(10, models.PTMidfielder, 3, 5.5)
+	midfielderZ = transferPlayer(11, models.PTMidfielder, 4, 5.6)
+	pricyX      = transferPlayer(12, models.PTMidfielder, 3, 7)
+	cheapW      = transferPlayer(13, models.PTForward, 4, 3)
+	teamNineX   = transferPlayer(14, models.PTMidfielder, 9, 5)
+	midfielderY = transferPlayer(15, models.PTMidfielder, 8, 5)
+	defenderE   = transferPlayer(16, models.PTDefender, 7, 4)
+	evenX       = transferPlayer(17, models.PTMidfielder, 3, 5)
+	evenY       = transferPlayer(18, models.PTMidfielder, 4, 5)
+)
+
+type planWant struct {
+	out  []models.PlayerID
+	in   []models.PlayerID
+	hits int
+	gain float32
+	bank float32
+}
+
+func TestPlannerPlan(t *testing.T) {
+	tests := []struct {
+		name          string
+		squad         Squad
+		players       []models.Player
+		points        map[models.PlayerID]float32
+		freeTransfers int
+		want          []planWant
+	}{
+		{
+			name:          "budget limit to the tenth",
+			squad:         Squad{Players: []models.Player{midfielderA}, Bank: 0.5},
+			players:       []models.Player{midfielderX, midfielderZ},
+			points:        map[models.PlayerID]float32{1: 2, 10: 10, 11: 15},
+			freeTransfers: 1,
+			want: []planWant{
+				{out: []models.PlayerID{1}, in: []models.PlayerID{10}, gain: 8, bank: 0},
+				{gain: 0, bank: 0.5},
+			},
+		},
+		{
+			name:          "double transfer pools both sales",
+			squad:         Squad{Players: []models.Player{midfielderA, forwardB}},
+			players:       []models.Player{pricyX, cheapW},
+			points:        map[models.PlayerID]float32{1: 2, 3: 1, 12: 10, 13: 2},
+			freeTransfers: 1,
+			want: []planWant{
+				{out: []models.PlayerID{1, 3}, in: []models.PlayerID{12, 13}, hits: 4, gain: 5, bank: 0},
+				{out: []models.PlayerID{3}, in: []models.PlayerID{13}, gain: 1, bank: 2},
+				{gain: 0, bank: 0},
+			},
+		},
+		{
+			name:          "three per team",
+			squad:         Squad{Players: []models.Player{midfielderA, defender1, defender2, defender3}},
+			players:       []models.Player{teamNineX, midfielderY, defenderE},
+			points:        map[models.PlayerID]float32{1: 2, 4: 5, 5: 5, 6: 5, 14: 20, 15: 6, 16: 5},
+			freeTransfers: 1,
+			want: []planWant{
+				{out: []models.PlayerID{1, 4}, in: []models.PlayerID{14, 16}, hits: 4, gain: 14, bank: 0},
+				{out: []models.PlayerID{1}, in: []models.PlayerID{15}, gain: 4, bank: 0},
+				{gain: 0, bank: 0},
+			},
+		},
+		{
+			name:          "no free transfers",
+			squad:         Squad{Players: []models.Player{midfielderA, midfielderB}},
+			players:       []models.Player{evenX, evenY},
+			points:        map[models.PlayerID]float32{1: 2, 2: 2, 17: 6, 18: 5},
+			freeTransfers: 0,
+			want: []planWant{
+				{gain: 0, bank: 0},
+				{out: []models.PlayerID{1}, in: []models.PlayerID{17}, hits: 4, gain: 0, bank: 0},
+				{out: []models.PlayerID{1, 2}, in: []models.PlayerID{17, 18}, hits: 8, gain: -1, bank: 0},
+			},
+		},
+		{
+			name:          "one free transfer",
+			squad:         Squad{Players: []models.Player{midfielderA, midfielderB}},
+			players:       []models.Player{evenX, evenY},
+			points:        map[models.PlayerID]float32{1: 2, 2: 2, 17: 6, 18: 5},
+			freeTransfers: 1,
+			want: []planWant{
+				{out: []models.PlayerID{1}, in: []models.PlayerID{17}, gain: 4, bank: 0},
+				{out: []models.PlayerID{1, 2}, in: []models.PlayerID{17, 18}, hits: 4, gain: 3, bank: 0},
+				{gain: 0, bank: 0},
+			},
+		},
+		{
+			name:          "two free transfers",
+			squad:         Squad{Players: []models.Player{midfielderA, midfielderB}},
+			players:       []models.Player{evenX, evenY},
+			points:        map[models.PlayerID]float32{1: 2, 2: 2, 17: 6, 18: 5},
+			freeTransfers: 2,
+			want: []planWant{
+				{out: []models.PlayerID{1, 2}, in: []models.PlayerID{17, 18}, gain: 7, bank: 0},
+				{out: []models.PlayerID{1}, in: []models.PlayerID{17}, gain: 4, bank: 0},
+				{gain: 0, bank: 0},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plans := NewPlanner(test.points, test.freeTransfers).Plan(test.squad, test.players)
+			if len(plans) != len(test.want) {
+				t.Fatalf("Plan() returned %d plans, want %d: %+v", len(plans), len(test.want), plans)
+			}
+			for n, plan := range plans {
+				want := test.want[n]
+				out := make([]models.PlayerID, 0)
+				in := make([]models.PlayerID, 0)
+				for _, transfer := range plan.Transfers {
+					out = append(out, transfer.Out.ID)
+					in = append(in, transfer.In.ID)
+				}
+				if want.out == nil {
+					want.out, want.in = []models.PlayerID{}, []models.PlayerID{}
+				}
+				if !reflect.DeepEqual(out, want.out) || !reflect.DeepEqual(in, want.in) {
+					t.Errorf("plan %d transfers %v for %v, want %v for %v", n, out, in, want.out, want.in)
+				}
+				if plan.Hits != want.hits {
+					t.Errorf("plan %d hits = %d, want %d", n, plan.Hits, want.hits)
+				}
+				if math.Abs(float64(plan.Gain-want.gain)) > 1e-4 {
+					t.Errorf("plan %d gain = %.2f, want %.2f", n, plan.Gain, want.gain)
+				}
+				if tenths(plan.Bank) != tenths(want.bank) {
+					t.Errorf("plan %d bank = £%.1fm, want £%.1fm", n, plan.Bank, want.bank)
+				}
+			}
+		})
+	}
+}
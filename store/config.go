@@ -0,0 +1,99 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultDataDir    = "./data"
+	defaultExportsDir = "./exports"
+
+	// MemoryPath keeps the database in memory for the life of the process.
+	MemoryPath = ":memory:"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	EnvPath       = "FPL_DB"
+	EnvName       = "FPL_DB_NAME"
+	EnvExportsDir = "FPL_EXPORTS_DIR"
+)
+
+// Config says where the database and its exports live. Leaving everything
+// empty uses ./data/data.sqlite and ./exports. Setting Name instead of Path
+// keeps a separate database and exports directory under that name, e.g. one
+// per season.
+type Config struct {
+	Path       string `json:"db"`
+	Name       string `json:"name"`
+	ExportsDir string `json:"exports_dir"`
+}
+
+// ReadConfig reads a JSON config file. A file that doesn't exist gives an
+// empty config.
+func ReadConfig(file string) (Config, error) {
+	var config Config
+	contents, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return config, fmt.Errorf("could not read config '%s': %w", file, err)
+	}
+	return config, nil
+}
+
+func ConfigFromEnv() Config {
+	return Config{
+		Path:       os.Getenv(EnvPath),
+		Name:       os.Getenv(EnvName),
+		ExportsDir: os.Getenv(EnvExportsDir),
+	}
+}
+
+// Merge returns c with every field that is set in override replaced. A path
+// or name in override replaces both of c's, so a name given on the command
+// line wins over a path from the config file.
+func (c Config) Merge(override Config) Config {
+	if override.Path != "" || override.Name != "" {
+		c.Path = override.Path
+		c.Name = override.Name
+	}
+	if override.ExportsDir != "" {
+		c.ExportsDir = override.ExportsDir
+	}
+	return c
+}
+
+func (c Config) DatabasePath() string {
+	switch {
+	case c.Path != "":
+		return c.Path
+	case c.Name != "":
+		return filepath.Join(defaultDataDir, c.Name+".sqlite")
+	}
+	return filepath.Join(defaultDataDir, "data.sqlite")
+}
+
+func (c Config) ExportsPath() string {
+	switch {
+	case c.ExportsDir != "":
+		return c.ExportsDir
+	case c.Name != "":
+		return filepath.Join(defaultExportsDir, c.Name)
+	}
+	return defaultExportsDir
+}
+
+func (c Config) InMemory() bool {
+	path := c.DatabasePath()
+	return path == MemoryPath || strings.Contains(path, "mode=memory")
+}
@@ -0,0 +1,361 @@
+package store
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrExportExists is returned by Dump when the gameweek has already been
+// exported and force isn't set.
+var ErrExportExists = errors.New("export already exists")
+
+// ErrNoCurrentGameweek is returned by Dump when no gameweek is current to name
+// the export after.
+var ErrNoCurrentGameweek = errors.New("no current gameweek to export")
+
+// ExportDir is where Dump writes a gameweek's tables.
+func (p *DataStore) ExportDir(gameweekID int) string {
+	return filepath.Join(p.Config.ExportsPath(), fmt.Sprintf("gw_%d", gameweekID))
+}
+
+// Dump writes every table to its own SQL file in ExportDir for the current
+// gameweek, in the same format as the sqlite3 CLI's .dump. An existing export
+// is only overwritten when force is set, and there's nothing to name the
+// export after without a current gameweek.
+func (p *DataStore) Dump(force bool) error {
+	db, err := p.Connect()
+	if err != nil {
+		return err
+	}
+
+	gameweekID := p.CurrentGameweek()
+	if gameweekID == 0 {
+		return ErrNoCurrentGameweek
+	}
+	exportDir := p.ExportDir(gameweekID)
+	if _, err := os.Stat(exportDir); err == nil && !force {
+		return fmt.Errorf("%w: '%s'", ErrExportExists, exportDir)
+	}
+	if err := os.MkdirAll(exportDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// Get a list of tables in the database
+	tables, err := getTableNames(db)
+	if err != nil {
+		return err
+	}
+
+	// Dump each table to a separate SQL file
+	for _, table := range tables {
+		if err = dumpTableToFile(db, table, exportDir); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println()
+
+	return nil
+}
+
+// ImportDump rebuilds the database from a directory written by Dump. Dumps
+// from older schemas are migrated before being copied in, and each table found
+// in the dump replaces the stored table's rows. Everything happens in one
+// transaction.
+func (p *DataStore) ImportDump(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no .sql files found in '%s'", dir)
+	}
+	sort.Strings(files)
+
+	// load the dump into a scratch database and bring it up to date so its
+	// tables match ours
+	scratch, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return err
+	}
+	defer scratch.Close()
+	scratch.SetMaxOpenConns(1)
+
+	dumpedTables := make([]string, 0)
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if _, err := scratch.Exec(string(contents)); err != nil {
+			return fmt.Errorf("could not load '%s': %w", file, err)
+		}
+		dumpedTables = append(dumpedTables, strings.TrimSuffix(filepath.Base(file), ".sql"))
+	}
+	legacyPlayers, err := hasColumn(scratch, "players", "gameweek_player_id")
+	if err != nil {
+		return err
+	}
+	if err := migrate(scratch); err != nil {
+		return err
+	}
+	// migrating splits a gameweek keyed players dump into players and
+	// snapshots
+	if legacyPlayers && !contains(dumpedTables, "player_snapshots") {
+		dumpedTables = append(dumpedTables, "player_snapshots")
+	}
+
+	db, err := p.Connect()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, table := range dumpedTables {
+		if table == "schema_version" {
+			continue
+		}
+		if err := copyTable(scratch, tx, table); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("could not import table '%s': %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// copyTable replaces the rows of a table in tx with those from src, copying
+// only the columns both tables have.
+func copyTable(src *sql.DB, tx *sql.Tx, table string) error {
+	srcColumns, err := columnNames(src, table)
+	if err != nil {
+		return err
+	}
+	dstColumns, err := columnNames(tx, table)
+	if err != nil {
+		return err
+	}
+	if len(dstColumns) == 0 {
+		// not a table we know about
+		return nil
+	}
+
+	columns := make([]string, 0)
+	for _, column := range srcColumns {
+		if contains(dstColumns, column) {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
+		return err
+	}
+
+	rows, err := src.Query(fmt.Sprintf("SELECT %s FROM %s", rawColumns(columns), table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	insert, err := tx.Prepare(fmt.Sprintf("INSERT OR REPLACE INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), placeholders))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for rows.Next() {
+		values, err := scanValues(rows, len(columns))
+		if err != nil {
+			return err
+		}
+		if _, err := insert.Exec(values...); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func columnNames(db execQueryer, table string) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make([]string, 0)
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			columnType string
+			notNull    bool
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+
+	return columns, rows.Err()
+}
+
+// getTableNames retrieves a list of table names from the SQLite database.
+// schema_version is among them, so an import only runs the migrations the
+// dump is missing.
+func getTableNames(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name;")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var tableName string
+		err := rows.Scan(&tableName)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, tableName)
+	}
+
+	return tables, rows.Err()
+}
+
+// dumpTableToFile dumps the schema and contents of the specified table to a SQL
+// file.
+func dumpTableToFile(db *sql.DB, tableName, exportDir string) error {
+	outputFile := filepath.Join(exportDir, tableName+".sql")
+
+	var schema string
+	err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type='table' AND name = ?", tableName).Scan(&schema)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	out := bufio.NewWriter(file)
+
+	fmt.Fprintln(out, "PRAGMA foreign_keys=OFF;")
+	fmt.Fprintln(out, "BEGIN TRANSACTION;")
+	fmt.Fprintf(out, "%s;\n", schema)
+
+	columns, err := columnNames(db, tableName)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.Query(fmt.Sprintf("SELECT %s FROM %s", rawColumns(columns), tableName))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values, err := scanValues(rows, len(columns))
+		if err != nil {
+			return err
+		}
+		literals := make([]string, len(values))
+		for i, value := range values {
+			literals[i] = sqlLiteral(value)
+		}
+		fmt.Fprintf(out, "INSERT INTO %s VALUES(%s);\n", tableName, strings.Join(literals, ","))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "COMMIT;")
+	if err := out.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("table '%s' dumped to '%s'\n", tableName, outputFile)
+
+	return nil
+}
+
+// rawColumns selects columns as expressions (+column) so the driver returns
+// values exactly as stored, rather than parsing DATETIME columns into times.
+func rawColumns(columns []string) string {
+	expressions := make([]string, len(columns))
+	for i, column := range columns {
+		expressions[i] = fmt.Sprintf("+%s AS %s", column, column)
+	}
+	return strings.Join(expressions, ", ")
+}
+
+func scanValues(rows *sql.Rows, count int) ([]any, error) {
+	values := make([]any, count)
+	pointers := make([]any, count)
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// sqlLiteral formats a scanned value the way the sqlite3 CLI's .dump does.
+func sqlLiteral(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		literal := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(literal, ".eIN") {
+			literal += ".0"
+		}
+		return literal
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return "X'" + hex.EncodeToString(v) + "'"
+	case string:
+		return quote(v)
+	default:
+		return quote(fmt.Sprint(v))
+	}
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package store
+
+import (
+	"better-fantasy/models"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newMemoryStore is an empty in-memory store exporting to a temporary
+// directory.
+func newMemoryStore(t *testing.T) DataStore {
+	t.Helper()
+	store, err := NewStore(Config{Path: MemoryPath, ExportsDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestDumpRoundTrip(t *testing.T) {
+	source := newMemoryStore(t)
+
+	home := models.Team{ID: 1, Name: "Arsenal", ShortName: "ARS"}
+	away := models.Team{ID: 2, Name: "Brentford", ShortName: "BRE"}
+	gameweeks := []models.Gameweek{
+		{ID: 14, Name: "Gameweek 14", Deadline: "2025-11-29T11:00:00Z", Finished: true},
+		{ID: 15, Name: "Gameweek 15", Deadline: "2025-12-02T18:00:00Z", IsCurrent: true},
+	}
+	fixture := models.Fixture{
+		ID: 150, Gameweek: &gameweeks[1], HomeTeam: &home, AwayTeam: &away,
+		HomeTeamDifficulty: 2, AwayTeamDifficulty: 4, DifficultyMajority: 2,
+		KickoffTime: time.Date(2025, 12, 3, 19, 30, 0, 0, time.UTC),
+		Started:     true, Finished: true, Minutes: 90, HomeTeamScore: 2, AwayTeamScore: 1,
+	}
+	player := models.Player{
+		ID: 7, Name: "Saka", Form: 6.5, TotalPoints: 80, Cost: "£10.1m", RawCost: 10.1,
+		Team: &home, Type: models.PlayerType{ID: models.PTMidfielder},
+		Stats: models.PlayerStats{Minutes: 1200, Goals: 6, Assists: 5, Starts: 14, MatchesPlayed: 14},
+		News:  "Knock - 75% chance of playing",
+	}
+
+	if err := source.StorePlayerType(models.PlayerType{ID: models.PTMidfielder, Name: "Midfielder", PluralName: "Midfielders", ShortName: "MID", TeamPlayerCount: 5, TeamMinPlayCount: 2, TeamMaxPlayCount: 5}); err != nil {
+		t.Fatalf("StorePlayerType() error = %v", err)
+	}
+	for _, team := range []models.Team{home, away} {
+		if err := source.StoreTeam(team); err != nil {
+			t.Fatalf("StoreTeam() error = %v", err)
+		}
+	}
+	for _, gameweek := range gameweeks {
+		if err := source.StoreGameweek(gameweek); err != nil {
+			t.Fatalf("StoreGameweek() error = %v", err)
+		}
+	}
+	if err := source.StoreFixture(fixture); err != nil {
+		t.Fatalf("StoreFixture() error = %v", err)
+	}
+	if err := source.StorePlayer(player); err != nil {
+		t.Fatalf("StorePlayer() error = %v", err)
+	}
+	if err := source.MarkImported(15); err != nil {
+		t.Fatalf("MarkImported() error = %v", err)
+	}
+
+	if err := source.Dump(false); err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if err := source.Dump(false); !errors.Is(err, ErrExportExists) {
+		t.Errorf("second Dump() error = %v, want %v", err, ErrExportExists)
+	}
+
+	imported := newMemoryStore(t)
+	if err := imported.ImportDump(source.ExportDir(15)); err != nil {
+		t.Fatalf("ImportDump() error = %v", err)
+	}
+	if gameweekID := imported.CurrentGameweek(); gameweekID != 15 {
+		t.Errorf("CurrentGameweek() = %d after the import, want 15", gameweekID)
+	}
+
+	wantGameweeks, err := source.GetGameweeks()
+	if err != nil {
+		t.Fatalf("GetGameweeks() error = %v", err)
+	}
+	gotGameweeks, err := imported.GetGameweeks()
+	if err != nil {
+		t.Fatalf("GetGameweeks() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotGameweeks, wantGameweeks) {
+		t.Errorf("imported gameweeks = %+v, want %+v", gotGameweeks, wantGameweeks)
+	}
+
+	fixtures, err := imported.GetFixtures(15, 15)
+	if err != nil {
+		t.Fatalf("GetFixtures() error = %v", err)
+	}
+	if len(fixtures) != 1 {
+		t.Fatalf("imported %d fixtures, want 1", len(fixtures))
+	}
+	got := fixtures[0]
+	if got.ID != fixture.ID || got.HomeTeam.ID != home.ID || got.AwayTeam.ID != away.ID || !got.KickoffTime.Equal(fixture.KickoffTime) || !got.Finished || got.HomeTeamScore != 2 || got.AwayTeamScore != 1 {
+		t.Errorf("imported fixture = %+v, want %+v", got, fixture)
+	}
+
+	players, err := imported.GetPlayers(PlayerFilter{})
+	if err != nil {
+		t.Fatalf("GetPlayers() error = %v", err)
+	}
+	if len(players) != 1 {
+		t.Fatalf("imported %d players, want 1", len(players))
+	}
+	gotPlayer := players[0]
+	if gotPlayer.ID != player.ID || gotPlayer.Name != player.Name || gotPlayer.Team.ID != home.ID || gotPlayer.Type.ID != models.PTMidfielder || gotPlayer.RawCost != player.RawCost || gotPlayer.Stats != player.Stats || gotPlayer.News != player.News {
+		t.Errorf("imported player = %+v, want %+v", gotPlayer, player)
+	}
+}
+
+func TestDumpWithoutCurrentGameweek(t *testing.T) {
+	store := newMemoryStore(t)
+	if err := store.Dump(false); !errors.Is(err, ErrNoCurrentGameweek) {
+		t.Errorf("Dump() error = %v, want %v", err, ErrNoCurrentGameweek)
+	}
+}
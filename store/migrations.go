@@ -0,0 +1,507 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *sql.Tx) error
+}
+
+// migrations are applied in order and each exactly once. Never edit or reorder
+// an existing migration, append a new one instead.
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "create tables",
+		Up:      createTables,
+	},
+	{
+		Version: 2,
+		Name:    "add players.news",
+		Up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "players", "news", "TEXT")
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create player_snapshots",
+		Up:      createPlayerSnapshots,
+	},
+	{
+		Version: 4,
+		Name:    "split gameweek keyed players into player_snapshots",
+		Up:      splitGameweekPlayers,
+	},
+	{
+		Version: 5,
+		Name:    "add player_fixtures scoring columns",
+		Up: func(tx *sql.Tx) error {
+			columns := []string{"goals_conceded", "saves", "penalties_saved", "penalties_missed", "own_goals", "defensive_contribution"}
+			for _, column := range columns {
+				if err := addColumnIfMissing(tx, "player_fixtures", column, "INT NOT NULL DEFAULT 0"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add player_fixtures.gameweek_id",
+		Up:      addPlayerFixtureGameweeks,
+	},
+	{
+		Version: 7,
+		Name:    "add player_fixtures.starts and count players.matches_played",
+		Up:      addPlayerFixtureStarts,
+	},
+	{
+		Version: 8,
+		Name:    "create player_chances",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS player_chances (
+				player_id INT NOT NULL,
+				gameweek_id INT NOT NULL,
+				chance REAL NOT NULL,
+				PRIMARY KEY (player_id, gameweek_id)
+			)`)
+			return err
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create manager_gameweeks",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS manager_gameweeks (
+				manager_id INT NOT NULL,
+				gameweek_id INT NOT NULL,
+				bank REAL NOT NULL,
+				PRIMARY KEY (manager_id, gameweek_id)
+			)`)
+			return err
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add fixtures results and create fixture_stats",
+		Up:      addFixtureResults,
+	},
+}
+
+type MigrationState struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt string
+}
+
+// Migrate applies every pending migration, each in its own transaction.
+func (p *DataStore) Migrate() error {
+	db, err := p.Connect()
+	if err != nil {
+		return err
+	}
+
+	return migrate(db)
+}
+
+func migrate(db *sql.DB) error {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := m.Up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		_, err = tx.Exec(
+			"INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+			m.Version,
+			m.Name,
+			time.Now().UTC().Format(time.RFC3339),
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrationStatus lists every known migration and whether it has been applied,
+// without applying anything.
+func (p *DataStore) MigrationStatus() ([]MigrationState, error) {
+	db, err := p.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		states = append(states, MigrationState{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return states, nil
+}
+
+// appliedMigrations returns when each applied migration version was applied.
+func appliedMigrations(db *sql.DB) (map[int]string, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string, 0)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// createTables is the schema as it was before migrations were introduced, so
+// it must tolerate the tables already existing.
+func createTables(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS imports (
+		gameweek_id INT PRIMARY KEY,
+		imported BOOLEAN NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS player_types (
+		id INTEGER PRIMARY KEY,
+		name TEXT,
+		plural_name TEXT,
+		short_name TEXT,
+		team_player_count INTEGER,
+		team_min_play_count INTEGER,
+		team_max_play_count INTEGER
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS players (
+		id INTEGER PRIMARY KEY,
+		name TEXT,
+		form REAL,
+		points_per_game REAL,
+		total_points INTEGER,
+		cost TEXT,
+		raw_cost REAL,
+		team_id INTEGER,
+		type_id INTEGER,
+		minutes INTEGER,
+		goals INTEGER,
+		assists INTEGER,
+		conceded INTEGER,
+		clean_sheets INTEGER,
+		yellow_cards INTEGER,
+		red_cards INTEGER,
+		bonus INTEGER,
+		starts INTEGER,
+		average_starts REAL,
+		matches_played REAL,
+		ict_index REAL,
+		ict_index_rank INTEGER,
+		most_captained BOOLEAN,
+		picked_percentage REAL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS teams (
+		id INTEGER PRIMARY KEY,
+		name VARCHAR,
+		short_name VARCHAR
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS gameweeks (
+		id INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		deadline DATETIME NOT NULL,
+		is_current BOOLEAN NOT NULL,
+		is_next BOOLEAN NOT NULL,
+		finished BOOLEAN NOT NULL,
+		most_captained_id INT
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS fixtures (
+		id INT PRIMARY KEY,
+		gameweek_id INT,
+		home_team_id INT NOT NULL,
+		away_team_id INT NOT NULL,
+		home_team_difficulty INT NOT NULL,
+		away_team_difficulty INT NOT NULL,
+		difficulty_majority INT NOT NULL,
+		CONSTRAINT fk_gameweek FOREIGN KEY (gameweek_id) REFERENCES gameweeks(id),
+		CONSTRAINT fk_home_team FOREIGN KEY (home_team_id) REFERENCES teams(id),
+		CONSTRAINT fk_away_team FOREIGN KEY (away_team_id) REFERENCES teams(id)
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS player_fixtures (
+		fixture_id INT NOT NULL,
+		player_id INT NOT NULL,
+		minutes INT NOT NULL,
+		played BOOLEAN NOT NULL,
+		points INT NOT NULL,
+		goals_scored INT NOT NULL,
+		assists INT NOT NULL,
+		yellow_cards INT NOT NULL,
+		red_cards INT NOT NULL,
+		bonus INT NOT NULL,
+		clean_sheet BOOLEAN NOT NULL,
+		was_home BOOLEAN NOT NULL,
+		PRIMARY KEY (fixture_id, player_id)
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE table IF NOT EXISTS manager_picks (
+		manager_id INT NOT NULL,
+		gameweek_id INT NOT NULL,
+		player_id INT NOT NULL,
+		is_captain BOOLEAN NOT NULL,
+		is_vice_captain BOOLEAN NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func createPlayerSnapshots(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS player_snapshots (
+		player_id INTEGER NOT NULL,
+		gameweek_id INTEGER NOT NULL,
+		form REAL,
+		points_per_game REAL,
+		total_points INTEGER,
+		cost TEXT,
+		raw_cost REAL,
+		minutes INTEGER,
+		goals INTEGER,
+		assists INTEGER,
+		conceded INTEGER,
+		clean_sheets INTEGER,
+		yellow_cards INTEGER,
+		red_cards INTEGER,
+		bonus INTEGER,
+		starts INTEGER,
+		average_starts REAL,
+		matches_played REAL,
+		ict_index REAL,
+		ict_index_rank INTEGER,
+		picked_percentage REAL,
+		news TEXT,
+		PRIMARY KEY (player_id, gameweek_id)
+	)`)
+	return err
+}
+
+// splitGameweekPlayers converts databases from when players were keyed by
+// gameweek_player_id (see exports/gw_14): every row becomes a snapshot and
+// players keeps the latest row per player.
+func splitGameweekPlayers(tx *sql.Tx) error {
+	legacy, err := hasColumn(tx, "players", "gameweek_player_id")
+	if err != nil || !legacy {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO player_snapshots (
+		player_id, gameweek_id, form, points_per_game, total_points, cost, raw_cost, minutes, goals, assists, conceded, clean_sheets, yellow_cards, red_cards, bonus, starts, average_starts, matches_played, ict_index, ict_index_rank, picked_percentage, news
+	) SELECT id, gameweek_id, form, points_per_game, total_points, cost, raw_cost, minutes, goals, assists, conceded, clean_sheets, yellow_cards, red_cards, bonus, starts, average_starts, matches_played, ict_index, ict_index_rank, picked_percentage, news
+	FROM players`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`CREATE TABLE players_latest (
+		id INTEGER PRIMARY KEY,
+		name TEXT,
+		form REAL,
+		points_per_game REAL,
+		total_points INTEGER,
+		cost TEXT,
+		raw_cost REAL,
+		team_id INTEGER,
+		type_id INTEGER,
+		minutes INTEGER,
+		goals INTEGER,
+		assists INTEGER,
+		conceded INTEGER,
+		clean_sheets INTEGER,
+		yellow_cards INTEGER,
+		red_cards INTEGER,
+		bonus INTEGER,
+		starts INTEGER,
+		average_starts REAL,
+		matches_played REAL,
+		ict_index REAL,
+		ict_index_rank INTEGER,
+		most_captained BOOLEAN,
+		picked_percentage REAL,
+		news TEXT
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO players_latest
+	SELECT id, name, form, points_per_game, total_points, cost, raw_cost, team_id, type_id, minutes, goals, assists, conceded, clean_sheets, yellow_cards, red_cards, bonus, starts, average_starts, matches_played, ict_index, ict_index_rank, most_captained, picked_percentage, news
+	FROM players
+	WHERE gameweek_id = (SELECT MAX(latest.gameweek_id) FROM players latest WHERE latest.id = players.id)
+	GROUP BY id`)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("DROP TABLE players"); err != nil {
+		return err
+	}
+	_, err = tx.Exec("ALTER TABLE players_latest RENAME TO players")
+	return err
+}
+
+// addPlayerFixtureGameweeks records which gameweek each player fixture was
+// played in, filling in existing rows from their fixture.
+func addPlayerFixtureGameweeks(tx *sql.Tx) error {
+	if err := addColumnIfMissing(tx, "player_fixtures", "gameweek_id", "INT"); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		UPDATE player_fixtures
+		SET gameweek_id = (SELECT gameweek_id FROM fixtures WHERE fixtures.id = player_fixtures.fixture_id)
+		WHERE gameweek_id IS NULL
+	`)
+	return err
+}
+
+// addPlayerFixtureStarts adds starts to player fixtures and fills in the
+// appearances which were never set on existing players.
+func addPlayerFixtureStarts(tx *sql.Tx) error {
+	if err := addColumnIfMissing(tx, "player_fixtures", "starts", "INT NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		UPDATE players
+		SET matches_played = (SELECT COUNT(*) FROM player_fixtures WHERE player_fixtures.player_id = players.id AND minutes > 0)
+	`)
+	return err
+}
+
+// addFixtureResults adds kickoff times, scores and progress to fixtures,
+// alongside each player's stats from them. Existing fixtures get their results
+// on the next update, since they now differ from what's fetched.
+func addFixtureResults(tx *sql.Tx) error {
+	columns := []struct {
+		name       string
+		definition string
+	}{
+		{"kickoff_time", "TEXT"},
+		{"started", "INT NOT NULL DEFAULT 0"},
+		{"finished", "INT NOT NULL DEFAULT 0"},
+		{"minutes", "INT NOT NULL DEFAULT 0"},
+		{"home_team_score", "INT"},
+		{"away_team_score", "INT"},
+	}
+	for _, column := range columns {
+		if err := addColumnIfMissing(tx, "fixtures", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS fixture_stats (
+		fixture_id INT NOT NULL,
+		identifier TEXT NOT NULL,
+		player_id INT NOT NULL,
+		home INT NOT NULL,
+		value INT NOT NULL,
+		PRIMARY KEY (fixture_id, identifier, player_id),
+		CONSTRAINT fk_fixture FOREIGN KEY (fixture_id) REFERENCES fixtures(id)
+	)`)
+	return err
+}
+
+type execQueryer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// addColumnIfMissing adds a column to an existing table, for databases whose
+// table already had the column when it was created.
+func addColumnIfMissing(db execQueryer, table string, column string, definition string) error {
+	exists, err := hasColumn(db, table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+func hasColumn(db execQueryer, table string, column string) (bool, error) {
+	columns, err := columnNames(db, table)
+	if err != nil {
+		return false, err
+	}
+	return contains(columns, column), nil
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// legacyExport is a gameweek exported before migrations, with a players row
+// for each player in each gameweek keyed by gameweek_player_id.
+const legacyExport = "../exports/gw_14"
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", MemoryPath)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	files, err := filepath.Glob(filepath.Join(legacyExport, "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no .sql files in '%s': %v", legacyExport, err)
+	}
+	for _, file := range files {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if _, err := db.Exec(string(contents)); err != nil {
+			t.Fatalf("could not load '%s': %v", file, err)
+		}
+	}
+	var legacyRows, legacyPlayers int
+	if err := db.QueryRow("SELECT COUNT(*), COUNT(DISTINCT id) FROM players").Scan(&legacyRows, &legacyPlayers); err != nil {
+		t.Fatalf("counting legacy players: %v", err)
+	}
+
+	if err := migrate(db); err != nil {
+		t.Fatalf("migrate() error = %v", err)
+	}
+	// a second run has nothing left to do
+	if err := migrate(db); err != nil {
+		t.Fatalf("second migrate() error = %v", err)
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		t.Fatalf("appliedMigrations() error = %v", err)
+	}
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			t.Errorf("migration %d (%s) wasn't recorded", m.Version, m.Name)
+		}
+	}
+
+	columns := []struct {
+		table  string
+		column string
+		want   bool
+	}{
+		{table: "players", column: "gameweek_player_id", want: false},
+		{table: "players", column: "news", want: true},
+		{table: "player_snapshots", column: "gameweek_id", want: true},
+		{table: "player_fixtures", column: "starts", want: true},
+		{table: "fixtures", column: "kickoff_time", want: true},
+	}
+	for _, c := range columns {
+		got, err := hasColumn(db, c.table, c.column)
+		if err != nil {
+			t.Fatalf("hasColumn(%s, %s) error = %v", c.table, c.column, err)
+		}
+		if got != c.want {
+			t.Errorf("hasColumn(%s, %s) = %t, want %t", c.table, c.column, got, c.want)
+		}
+	}
+
+	var snapshots int
+	if err := db.QueryRow("SELECT COUNT(*) FROM player_snapshots").Scan(&snapshots); err != nil {
+		t.Fatalf("counting snapshots: %v", err)
+	}
+	if snapshots != legacyRows {
+		t.Errorf("%d player snapshots, want one for each of the %d legacy rows", snapshots, legacyRows)
+	}
+
+	store := DataStore{Connection: db}
+	players, err := store.GetPlayers(PlayerFilter{})
+	if err != nil {
+		t.Fatalf("GetPlayers() error = %v", err)
+	}
+	if len(players) != legacyPlayers {
+		t.Errorf("GetPlayers() returned %d players, want %d", len(players), legacyPlayers)
+	}
+}
@@ -0,0 +1,661 @@
+package store
+
+import (
+	"better-fantasy/models"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PlayerFilter narrows GetPlayers. Zero values match every player.
+type PlayerFilter struct {
+	Position   models.PlayerTypeID
+	TeamID     models.TeamID
+	MaxCost    float32
+	MinMinutes int
+}
+
+func (f PlayerFilter) matches(player models.Player) bool {
+	if f.Position != 0 && player.Type.ID != f.Position {
+		return false
+	}
+	if f.TeamID != 0 && (player.Team == nil || player.Team.ID != f.TeamID) {
+		return false
+	}
+	if f.MaxCost > 0 && player.RawCost > f.MaxCost {
+		return false
+	}
+	if player.Stats.Minutes < f.MinMinutes {
+		return false
+	}
+	return true
+}
+
+// catalogue holds everything players and fixtures point to, so the models
+// returned by the read methods share the same teams and gameweeks.
+type catalogue struct {
+	playerTypes map[models.PlayerTypeID]models.PlayerType
+	gameweeks   map[models.GameweekID]*models.Gameweek
+	teams       map[models.TeamID]*models.Team
+	teamOrder   []*models.Team
+	fixtures    []*models.Fixture
+}
+
+// GetPlayer returns a player with their team, type and full history.
+func (p *DataStore) GetPlayer(playerID models.PlayerID) (models.Player, error) {
+	cat, err := p.loadCatalogue()
+	if err != nil {
+		return models.Player{}, err
+	}
+
+	players, err := p.loadPlayers(cat, "WHERE id = ?", playerID)
+	if err != nil {
+		return models.Player{}, err
+	}
+	if len(players) == 0 {
+		return models.Player{}, sql.ErrNoRows
+	}
+
+	return players[0], nil
+}
+
+// GetPlayers returns every player matching filter, ordered by ID. Each
+// player's Team has its full squad and fixture list.
+func (p *DataStore) GetPlayers(filter PlayerFilter) ([]models.Player, error) {
+	cat, err := p.loadCatalogue()
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := p.loadPlayers(cat, "")
+	if err != nil {
+		return nil, err
+	}
+	cat.attachPlayers(players)
+
+	filtered := make([]models.Player, 0)
+	for _, player := range players {
+		if filter.matches(player) {
+			filtered = append(filtered, player)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (p *DataStore) GetPlayerTypes() ([]models.PlayerType, error) {
+	cat, err := p.loadCatalogue()
+	if err != nil {
+		return nil, err
+	}
+
+	playerTypes := make([]models.PlayerType, 0, len(cat.playerTypes))
+	for _, playerType := range cat.playerTypes {
+		playerTypes = append(playerTypes, playerType)
+	}
+	sort.Slice(playerTypes, func(i, j int) bool {
+		return playerTypes[i].ID < playerTypes[j].ID
+	})
+
+	return playerTypes, nil
+}
+
+// GetTeams returns every team, ordered by ID, with its players and fixtures.
+func (p *DataStore) GetTeams() ([]*models.Team, error) {
+	cat, err := p.loadCatalogue()
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := p.loadPlayers(cat, "")
+	if err != nil {
+		return nil, err
+	}
+	cat.attachPlayers(players)
+
+	return cat.teamOrder, nil
+}
+
+// GetFixtures returns the fixtures scheduled from one gameweek to another
+// (inclusive), ordered by gameweek then ID.
+func (p *DataStore) GetFixtures(fromGameweek int, toGameweek int) ([]*models.Fixture, error) {
+	cat, err := p.loadCatalogue()
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make([]*models.Fixture, 0)
+	for _, fixture := range cat.fixtures {
+		if !fixture.Scheduled() {
+			continue
+		}
+		if int(fixture.Gameweek.ID) < fromGameweek || int(fixture.Gameweek.ID) > toGameweek {
+			continue
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	return fixtures, nil
+}
+
+// GetUnscheduledFixtures returns the fixtures without a gameweek, ordered by
+// ID.
+func (p *DataStore) GetUnscheduledFixtures() ([]*models.Fixture, error) {
+	cat, err := p.loadCatalogue()
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make([]*models.Fixture, 0)
+	for _, fixture := range cat.fixtures {
+		if !fixture.Scheduled() {
+			fixtures = append(fixtures, fixture)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// GetGameweeks returns every gameweek in order.
+func (p *DataStore) GetGameweeks() ([]models.Gameweek, error) {
+	cat, err := p.loadCatalogue()
+	if err != nil {
+		return nil, err
+	}
+
+	gameweeks := make([]models.Gameweek, 0, len(cat.gameweeks))
+	for _, gameweek := range cat.gameweeks {
+		gameweeks = append(gameweeks, *gameweek)
+	}
+	sort.Slice(gameweeks, func(i, j int) bool {
+		return gameweeks[i].ID < gameweeks[j].ID
+	})
+
+	return gameweeks, nil
+}
+
+// GetManagerPicks returns a manager's picks for a gameweek. A managerID or
+// gameweekID of 0 matches every manager or gameweek.
+func (p *DataStore) GetManagerPicks(managerID int, gameweekID int) ([]models.ManagerPick, error) {
+	db, err := p.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	if managerID != 0 {
+		conditions = append(conditions, "manager_id = ?")
+		args = append(args, managerID)
+	}
+	if gameweekID != 0 {
+		conditions = append(conditions, "gameweek_id = ?")
+		args = append(args, gameweekID)
+	}
+	query := "SELECT manager_id, gameweek_id, player_id, is_captain, is_vice_captain FROM `manager_picks`"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY manager_id, gameweek_id, rowid"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	picks := make([]models.ManagerPick, 0)
+	for rows.Next() {
+		var pick models.ManagerPick
+		err := rows.Scan(
+			&pick.ManagerID,
+			&pick.GameweekID,
+			&pick.PlayerID,
+			&pick.IsCaptain,
+			&pick.IsViceCaptain,
+		)
+		if err != nil {
+			return nil, err
+		}
+		picks = append(picks, pick)
+	}
+
+	return picks, rows.Err()
+}
+
+// GetManagerGameweek returns a manager's bank in a gameweek, which is 0 when
+// their picks weren't imported for it.
+func (p *DataStore) GetManagerGameweek(managerID int, gameweekID int) (models.ManagerGameweek, error) {
+	db, err := p.Connect()
+	if err != nil {
+		return models.ManagerGameweek{}, err
+	}
+
+	gameweek := models.ManagerGameweek{
+		ManagerID:  managerID,
+		GameweekID: models.GameweekID(gameweekID),
+	}
+	row := db.QueryRow("SELECT bank FROM `manager_gameweeks` WHERE manager_id = ? AND gameweek_id = ?", managerID, gameweekID)
+	err = row.Scan(&gameweek.Bank)
+	if err != nil && err != sql.ErrNoRows {
+		return models.ManagerGameweek{}, err
+	}
+
+	return gameweek, nil
+}
+
+func (p *DataStore) loadCatalogue() (*catalogue, error) {
+	db, err := p.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	cat := &catalogue{
+		playerTypes: make(map[models.PlayerTypeID]models.PlayerType, 0),
+		gameweeks:   make(map[models.GameweekID]*models.Gameweek, 0),
+		teams:       make(map[models.TeamID]*models.Team, 0),
+	}
+
+	typeRows, err := db.Query("SELECT id, name, plural_name, short_name, team_player_count, team_min_play_count, team_max_play_count FROM `player_types`")
+	if err != nil {
+		return nil, err
+	}
+	defer typeRows.Close()
+	for typeRows.Next() {
+		var playerType models.PlayerType
+		err := typeRows.Scan(
+			&playerType.ID,
+			&playerType.Name,
+			&playerType.PluralName,
+			&playerType.ShortName,
+			&playerType.TeamPlayerCount,
+			&playerType.TeamMinPlayCount,
+			&playerType.TeamMaxPlayCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		cat.playerTypes[playerType.ID] = playerType
+	}
+	if err := typeRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// +deadline selects the deadline as an expression so the driver returns it
+	// as stored rather than parsing the DATETIME column, as rawColumns does
+	gameweekRows, err := db.Query("SELECT id, name, +deadline, is_current, is_next, finished, most_captained_id FROM `gameweeks`")
+	if err != nil {
+		return nil, err
+	}
+	defer gameweekRows.Close()
+	for gameweekRows.Next() {
+		var gameweek models.Gameweek
+		var mostCaptainedID sql.NullInt64
+		err := gameweekRows.Scan(
+			&gameweek.ID,
+			&gameweek.Name,
+			&gameweek.Deadline,
+			&gameweek.IsCurrent,
+			&gameweek.IsNext,
+			&gameweek.Finished,
+			&mostCaptainedID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		gameweek.MostCaptainedID = models.PlayerID(mostCaptainedID.Int64)
+		cat.gameweeks[gameweek.ID] = &gameweek
+	}
+	if err := gameweekRows.Err(); err != nil {
+		return nil, err
+	}
+
+	teamRows, err := db.Query("SELECT id, name, short_name FROM `teams` ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer teamRows.Close()
+	for teamRows.Next() {
+		var team models.Team
+		if err := teamRows.Scan(&team.ID, &team.Name, &team.ShortName); err != nil {
+			return nil, err
+		}
+		cat.teams[team.ID] = &team
+		cat.teamOrder = append(cat.teamOrder, &team)
+	}
+	if err := teamRows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats, err := getFixtureStats(db)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtureRows, err := db.Query("SELECT " + fixtureColumns + " FROM `fixtures` ORDER BY gameweek_id, id")
+	if err != nil {
+		return nil, err
+	}
+	defer fixtureRows.Close()
+	for fixtureRows.Next() {
+		fixture, gameweekID, homeTeamID, awayTeamID, err := scanFixture(fixtureRows)
+		if err != nil {
+			return nil, err
+		}
+		if gameweekID.Valid {
+			fixture.Gameweek = cat.gameweeks[models.GameweekID(gameweekID.Int64)]
+		}
+		fixture.Stats = stats[fixture.ID]
+		fixture.HomeTeam = cat.teams[homeTeamID]
+		fixture.AwayTeam = cat.teams[awayTeamID]
+		if fixture.HomeTeam == nil || fixture.AwayTeam == nil {
+			continue
+		}
+		cat.fixtures = append(cat.fixtures, &fixture)
+		fixture.HomeTeam.Fixtures = append(fixture.HomeTeam.Fixtures, fixture)
+		fixture.AwayTeam.Fixtures = append(fixture.AwayTeam.Fixtures, fixture)
+	}
+	if err := fixtureRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cat, nil
+}
+
+// fixtureColumns are the fixture columns scanFixture reads.
+const fixtureColumns = "id, gameweek_id, home_team_id, away_team_id, home_team_difficulty, away_team_difficulty, difficulty_majority, kickoff_time, started, finished, minutes, home_team_score, away_team_score"
+
+// scanFixture reads a row of fixtureColumns, leaving the caller to look up
+// its gameweek and teams.
+func scanFixture(rows *sql.Rows) (models.Fixture, sql.NullInt64, models.TeamID, models.TeamID, error) {
+	var fixture models.Fixture
+	var gameweekID, homeScore, awayScore sql.NullInt64
+	var kickoffTime sql.NullString
+	var homeTeamID, awayTeamID models.TeamID
+	err := rows.Scan(
+		&fixture.ID,
+		&gameweekID,
+		&homeTeamID,
+		&awayTeamID,
+		&fixture.HomeTeamDifficulty,
+		&fixture.AwayTeamDifficulty,
+		&fixture.DifficultyMajority,
+		&kickoffTime,
+		&fixture.Started,
+		&fixture.Finished,
+		&fixture.Minutes,
+		&homeScore,
+		&awayScore,
+	)
+	if err != nil {
+		return fixture, gameweekID, homeTeamID, awayTeamID, err
+	}
+	if kickoffTime.Valid {
+		fixture.KickoffTime, err = time.Parse(time.RFC3339, kickoffTime.String)
+		if err != nil {
+			return fixture, gameweekID, homeTeamID, awayTeamID, err
+		}
+	}
+	fixture.HomeTeamScore = int(homeScore.Int64)
+	fixture.AwayTeamScore = int(awayScore.Int64)
+	return fixture, gameweekID, homeTeamID, awayTeamID, nil
+}
+
+// getFixtureStats loads every fixture's stats, by fixture.
+func getFixtureStats(db *sql.DB) (map[models.FixtureID][]models.FixtureStat, error) {
+	rows, err := db.Query("SELECT fixture_id, identifier, player_id, home, value FROM `fixture_stats` ORDER BY fixture_id, identifier, home DESC, value DESC, player_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[models.FixtureID][]models.FixtureStat, 0)
+	for rows.Next() {
+		var fixtureID models.FixtureID
+		var stat models.FixtureStat
+		if err := rows.Scan(&fixtureID, &stat.Identifier, &stat.PlayerID, &stat.Home, &stat.Value); err != nil {
+			return nil, err
+		}
+		stats[fixtureID] = append(stats[fixtureID], stat)
+	}
+	return stats, rows.Err()
+}
+
+// attachPlayers sets each team's squad.
+func (c *catalogue) attachPlayers(players []models.Player) {
+	for _, team := range c.teamOrder {
+		team.Players = nil
+	}
+	for _, player := range players {
+		if player.Team != nil {
+			player.Team.Players = append(player.Team.Players, player)
+		}
+	}
+}
+
+const playerColumns = "id, name, form, points_per_game, total_points, cost, raw_cost, team_id, type_id, minutes, goals, assists, conceded, clean_sheets, yellow_cards, red_cards, bonus, starts, average_starts, matches_played, ict_index, ict_index_rank, most_captained, picked_percentage, news"
+
+// loadPlayers returns the players matching where, ordered by ID, with their
+// histories.
+func (p *DataStore) loadPlayers(cat *catalogue, where string, args ...any) ([]models.Player, error) {
+	db, err := p.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	playerRows, err := db.Query("SELECT "+playerColumns+" FROM `players` "+where+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer playerRows.Close()
+
+	players := make([]models.Player, 0)
+	indexesByID := make(map[models.PlayerID]int, 0)
+	for playerRows.Next() {
+		var player models.Player
+		var teamID models.TeamID
+		var typeID models.PlayerTypeID
+		var mostCaptained sql.NullBool
+		var news sql.NullString
+		err := playerRows.Scan(
+			&player.ID,
+			&player.Name,
+			&player.Form,
+			&player.PointsPerGame,
+			&player.TotalPoints,
+			&player.Cost,
+			&player.RawCost,
+			&teamID,
+			&typeID,
+			&player.Stats.Minutes,
+			&player.Stats.Goals,
+			&player.Stats.Assists,
+			&player.Stats.Conceded,
+			&player.Stats.CleanSheets,
+			&player.Stats.YellowCards,
+			&player.Stats.RedCards,
+			&player.Stats.Bonus,
+			&player.Stats.Starts,
+			&player.Stats.AverageStarts,
+			&player.Stats.MatchesPlayed,
+			&player.Stats.ICTIndex,
+			&player.Stats.ICTIndexRank,
+			&mostCaptained,
+			&player.PickedPercentage,
+			&news,
+		)
+		if err != nil {
+			return nil, err
+		}
+		player.Team = cat.teams[teamID]
+		player.Type = cat.playerTypes[typeID]
+		player.MostCaptained = mostCaptained.Bool
+		player.News = news.String
+		player.History = make(map[models.FixtureID]models.PlayerFixture, 0)
+		player.ChanceOfPlaying = make(models.PlayerRoundProbability, 0)
+		indexesByID[player.ID] = len(players)
+		players = append(players, player)
+	}
+	if err := playerRows.Err(); err != nil {
+		return nil, err
+	}
+
+	chanceQuery := "SELECT player_id, gameweek_id, chance FROM `player_chances`"
+	chanceArgs := make([]any, 0)
+	if len(players) == 1 {
+		chanceQuery += " WHERE player_id = ?"
+		chanceArgs = append(chanceArgs, players[0].ID)
+	}
+	chanceRows, err := db.Query(chanceQuery, chanceArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer chanceRows.Close()
+
+	for chanceRows.Next() {
+		var playerID models.PlayerID
+		var gameweekID models.GameweekID
+		var chance float32
+		if err := chanceRows.Scan(&playerID, &gameweekID, &chance); err != nil {
+			return nil, err
+		}
+		if i, ok := indexesByID[playerID]; ok {
+			players[i].ChanceOfPlaying[gameweekID] = chance
+		}
+	}
+	if err := chanceRows.Err(); err != nil {
+		return nil, err
+	}
+
+	historyQuery := "SELECT fixture_id, player_id, minutes, played, points, goals_scored, assists, yellow_cards, red_cards, bonus, clean_sheet, was_home, goals_conceded, saves, penalties_saved, penalties_missed, own_goals, defensive_contribution, gameweek_id, starts FROM `player_fixtures`"
+	historyArgs := make([]any, 0)
+	if len(players) == 1 {
+		historyQuery += " WHERE player_id = ?"
+		historyArgs = append(historyArgs, players[0].ID)
+	}
+	historyRows, err := db.Query(historyQuery, historyArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer historyRows.Close()
+
+	for historyRows.Next() {
+		var fixture models.PlayerFixture
+		var gameweekID sql.NullInt64
+		err := historyRows.Scan(
+			&fixture.FixtureID,
+			&fixture.PlayerID,
+			&fixture.Minutes,
+			&fixture.Played,
+			&fixture.Points,
+			&fixture.GoalsScored,
+			&fixture.Assists,
+			&fixture.YellowCards,
+			&fixture.RedCards,
+			&fixture.Bonus,
+			&fixture.CleanSheet,
+			&fixture.WasHome,
+			&fixture.GoalsConceded,
+			&fixture.Saves,
+			&fixture.PenaltiesSaved,
+			&fixture.PenaltiesMissed,
+			&fixture.OwnGoals,
+			&fixture.DefensiveContribution,
+			&gameweekID,
+			&fixture.Starts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		fixture.GameweekID = models.GameweekID(gameweekID.Int64)
+		if i, ok := indexesByID[fixture.PlayerID]; ok {
+			players[i].History[fixture.FixtureID] = fixture
+		}
+	}
+
+	return players, historyRows.Err()
+}
+
+// GetPlayerSnapshots returns a player's snapshots from one gameweek to another
+// (inclusive), oldest first. Gameweeks the player wasn't imported in are
+// missing rather than zero.
+func (p *DataStore) GetPlayerSnapshots(playerID models.PlayerID, fromGameweek int, toGameweek int) ([]models.PlayerSnapshot, error) {
+	db, err := p.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(
+		"SELECT "+snapshotColumns+" FROM `player_snapshots` WHERE `player_id` = ? AND `gameweek_id` BETWEEN ? AND ? ORDER BY `gameweek_id`",
+		playerID,
+		fromGameweek,
+		toGameweek,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+// GetGameweekSnapshots returns every player's snapshot for a gameweek.
+func (p *DataStore) GetGameweekSnapshots(gameweekID int) ([]models.PlayerSnapshot, error) {
+	db, err := p.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(
+		"SELECT "+snapshotColumns+" FROM `player_snapshots` WHERE `gameweek_id` = ? ORDER BY `player_id`",
+		gameweekID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSnapshots(rows)
+}
+
+const snapshotColumns = "player_id, gameweek_id, form, points_per_game, total_points, cost, raw_cost, minutes, goals, assists, conceded, clean_sheets, yellow_cards, red_cards, bonus, starts, average_starts, matches_played, ict_index, ict_index_rank, picked_percentage, news"
+
+func scanSnapshots(rows *sql.Rows) ([]models.PlayerSnapshot, error) {
+	snapshots := make([]models.PlayerSnapshot, 0)
+	for rows.Next() {
+		var snapshot models.PlayerSnapshot
+		var news sql.NullString
+		err := rows.Scan(
+			&snapshot.PlayerID,
+			&snapshot.GameweekID,
+			&snapshot.Form,
+			&snapshot.PointsPerGame,
+			&snapshot.TotalPoints,
+			&snapshot.Cost,
+			&snapshot.RawCost,
+			&snapshot.Stats.Minutes,
+			&snapshot.Stats.Goals,
+			&snapshot.Stats.Assists,
+			&snapshot.Stats.Conceded,
+			&snapshot.Stats.CleanSheets,
+			&snapshot.Stats.YellowCards,
+			&snapshot.Stats.RedCards,
+			&snapshot.Stats.Bonus,
+			&snapshot.Stats.Starts,
+			&snapshot.Stats.AverageStarts,
+			&snapshot.Stats.MatchesPlayed,
+			&snapshot.Stats.ICTIndex,
+			&snapshot.Stats.ICTIndexRank,
+			&snapshot.PickedPercentage,
+			&news,
+		)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.News = news.String
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,537 @@
+package store
+
+import (
+	"better-fantasy/api"
+	"better-fantasy/models"
+	"database/sql"
+	"time"
+)
+
+const (
+	insertPlayerTypeQuery = `
+		INSERT OR REPLACE INTO player_types (id, name, plural_name, short_name, team_player_count, team_min_play_count, team_max_play_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	insertTeamQuery = `
+		INSERT OR REPLACE INTO teams (id, name, short_name)
+		VALUES (?, ?, ?)
+	`
+	insertGameweekQuery = `
+		INSERT OR REPLACE INTO gameweeks (id, name, deadline, is_current, is_next, finished, most_captained_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	insertFixtureQuery = `
+		INSERT OR REPLACE INTO fixtures (
+		id,
+		gameweek_id,
+		home_team_id,
+		away_team_id,
+		home_team_difficulty,
+		away_team_difficulty,
+		difficulty_majority,
+		kickoff_time,
+		started,
+		finished,
+		minutes,
+		home_team_score,
+		away_team_score
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	deleteFixtureStatsQuery = `
+		DELETE FROM fixture_stats WHERE fixture_id = ?
+	`
+	insertFixtureStatQuery = `
+		INSERT OR REPLACE INTO fixture_stats (fixture_id, identifier, player_id, home, value) VALUES (?, ?, ?, ?, ?)
+	`
+	insertPlayerQuery = `
+		INSERT OR REPLACE INTO players (id, name, form, points_per_game, total_points, cost, raw_cost, team_id, type_id, minutes, goals, assists, conceded, clean_sheets, yellow_cards, red_cards, bonus, starts, average_starts, matches_played, ict_index, ict_index_rank, most_captained, picked_percentage, news)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	insertPlayerSnapshotQuery = `
+		INSERT OR REPLACE INTO player_snapshots (player_id, gameweek_id, form, points_per_game, total_points, cost, raw_cost, minutes, goals, assists, conceded, clean_sheets, yellow_cards, red_cards, bonus, starts, average_starts, matches_played, ict_index, ict_index_rank, picked_percentage, news)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	insertPlayerFixtureQuery = `
+		INSERT OR REPLACE INTO player_fixtures (
+			fixture_id,
+			player_id,
+			minutes,
+			played,
+			points,
+			goals_scored,
+			assists,
+			yellow_cards,
+			red_cards,
+			bonus,
+			clean_sheet,
+			was_home,
+			goals_conceded,
+			saves,
+			penalties_saved,
+			penalties_missed,
+			own_goals,
+			defensive_contribution,
+			gameweek_id,
+			starts
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	deletePlayerChancesQuery = `
+		DELETE FROM player_chances WHERE player_id = ?
+	`
+	insertPlayerChanceQuery = `
+		INSERT OR REPLACE INTO player_chances (player_id, gameweek_id, chance) VALUES (?, ?, ?)
+	`
+	deletePicksQuery = `
+		DELETE FROM manager_picks WHERE manager_id = ? AND gameweek_id = ?
+	`
+	insertPickQuery = `
+		INSERT OR IGNORE INTO manager_picks (
+			manager_id,
+			gameweek_id,
+			player_id,
+			is_captain,
+			is_vice_captain
+		) VALUES (?, ?, ?, ?, ?)`
+	insertManagerGameweekQuery = `
+		INSERT OR REPLACE INTO manager_gameweeks (manager_id, gameweek_id, bank) VALUES (?, ?, ?)
+	`
+	insertImportQuery = `
+		INSERT OR IGNORE INTO imports (
+			gameweek_id,
+			imported
+		) VALUES (?, ?)
+	`
+)
+
+// statements are the prepared writes used by a single import transaction.
+type statements struct {
+	playerType      *sql.Stmt
+	team            *sql.Stmt
+	gameweek        *sql.Stmt
+	fixture         *sql.Stmt
+	deleteStats     *sql.Stmt
+	fixtureStat     *sql.Stmt
+	player          *sql.Stmt
+	playerSnapshot  *sql.Stmt
+	playerFixture   *sql.Stmt
+	deleteChances   *sql.Stmt
+	playerChance    *sql.Stmt
+	deletePicks     *sql.Stmt
+	pick            *sql.Stmt
+	managerGameweek *sql.Stmt
+	imported        *sql.Stmt
+}
+
+func prepareStatements(tx *sql.Tx) (*statements, error) {
+	stmts := &statements{}
+	targets := []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&stmts.playerType, insertPlayerTypeQuery},
+		{&stmts.team, insertTeamQuery},
+		{&stmts.gameweek, insertGameweekQuery},
+		{&stmts.fixture, insertFixtureQuery},
+		{&stmts.deleteStats, deleteFixtureStatsQuery},
+		{&stmts.fixtureStat, insertFixtureStatQuery},
+		{&stmts.player, insertPlayerQuery},
+		{&stmts.playerSnapshot, insertPlayerSnapshotQuery},
+		{&stmts.playerFixture, insertPlayerFixtureQuery},
+		{&stmts.deleteChances, deletePlayerChancesQuery},
+		{&stmts.playerChance, insertPlayerChanceQuery},
+		{&stmts.deletePicks, deletePicksQuery},
+		{&stmts.pick, insertPickQuery},
+		{&stmts.managerGameweek, insertManagerGameweekQuery},
+		{&stmts.imported, insertImportQuery},
+	}
+	for _, target := range targets {
+		stmt, err := tx.Prepare(target.query)
+		if err != nil {
+			stmts.Close()
+			return nil, err
+		}
+		*target.stmt = stmt
+	}
+	return stmts, nil
+}
+
+func (s *statements) Close() {
+	for _, stmt := range []*sql.Stmt{
+		s.playerType,
+		s.team,
+		s.gameweek,
+		s.fixture,
+		s.deleteStats,
+		s.fixtureStat,
+		s.player,
+		s.playerSnapshot,
+		s.playerFixture,
+		s.deleteChances,
+		s.playerChance,
+		s.deletePicks,
+		s.pick,
+		s.managerGameweek,
+		s.imported,
+	} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+}
+
+// inTransaction runs f with prepared statements inside a single transaction,
+// committing only if f succeeds.
+func (p *DataStore) inTransaction(f func(tx *sql.Tx, stmts *statements) error) error {
+	db, err := p.Connect()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmts, err := prepareStatements(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmts.Close()
+
+	if err := f(tx, stmts); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// StoreData writes a full import in one transaction and marks the current
+// gameweek as imported. Nothing is written if any part fails. dumpData clears
+// the database first and only keeps the current gameweek's fixtures, ready for
+// Dump.
+func (p *DataStore) StoreData(data *api.Data, dumpData bool) error {
+	currentGameweek := data.CurrentGameweek()
+
+	return p.inTransaction(func(tx *sql.Tx, stmts *statements) error {
+		// ensures dump only contains data for specific gw
+		if dumpData {
+			if err := nuke(tx); err != nil {
+				return err
+			}
+		}
+
+		if err := storeReferenceData(stmts, data); err != nil {
+			return err
+		}
+
+		for _, fixture := range data.Fixtures {
+			// ensures we see only fixtures for specific gameweek
+			if dumpData && (currentGameweek == nil || !fixture.Scheduled() || fixture.Gameweek.ID != currentGameweek.ID) {
+				continue
+			}
+
+			if err := storeFixture(stmts, *fixture); err != nil {
+				return err
+			}
+		}
+
+		for _, player := range data.Players {
+			if err := storePlayer(stmts, player, currentGameweek); err != nil {
+				return err
+			}
+		}
+
+		if err := storePicks(stmts, data.ManagerPicks, data.ManagerGameweeks); err != nil {
+			return err
+		}
+
+		if currentGameweek != nil {
+			if _, err := stmts.imported.Exec(currentGameweek.ID, true); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+type RefreshSummary struct {
+	Players   int
+	Histories int
+	Fixtures  int
+}
+
+// UpdateData refreshes an already imported gameweek in place. Every player row
+// is rewritten but histories are only stored for players fetched with one (see
+// api.FetchOptions.Known), and only fixtures that differ from the stored ones
+// are written.
+func (p *DataStore) UpdateData(data *api.Data) (RefreshSummary, error) {
+	summary := RefreshSummary{}
+	currentGameweek := data.CurrentGameweek()
+
+	storedFixtures, err := p.getStoredFixtures()
+	if err != nil {
+		return summary, err
+	}
+
+	err = p.inTransaction(func(tx *sql.Tx, stmts *statements) error {
+		if err := storeReferenceData(stmts, data); err != nil {
+			return err
+		}
+
+		for _, fixture := range data.Fixtures {
+			if stored, ok := storedFixtures[fixture.ID]; ok && !fixtureChanged(stored, *fixture) {
+				continue
+			}
+			if err := storeFixture(stmts, *fixture); err != nil {
+				return err
+			}
+			summary.Fixtures++
+		}
+
+		for _, player := range data.Players {
+			if err := storePlayer(stmts, player, currentGameweek); err != nil {
+				return err
+			}
+			summary.Players++
+			if player.History != nil {
+				summary.Histories++
+			}
+		}
+
+		return storePicks(stmts, data.ManagerPicks, data.ManagerGameweeks)
+	})
+	if err != nil {
+		return RefreshSummary{}, err
+	}
+
+	return summary, nil
+}
+
+// storeReferenceData writes player types, teams and gameweeks.
+func storeReferenceData(stmts *statements, data *api.Data) error {
+	for _, playerType := range data.PlayerTypes {
+		if _, err := stmts.playerType.Exec(playerTypeArgs(playerType)...); err != nil {
+			return err
+		}
+	}
+
+	for _, team := range data.Teams {
+		if _, err := stmts.team.Exec(teamArgs(*team)...); err != nil {
+			return err
+		}
+	}
+
+	for _, gameweek := range data.Gameweeks {
+		if _, err := stmts.gameweek.Exec(gameweekArgs(gameweek)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// storeFixture writes a fixture and replaces its stats.
+func storeFixture(stmts *statements, fixture models.Fixture) error {
+	if _, err := stmts.fixture.Exec(fixtureArgs(fixture)...); err != nil {
+		return err
+	}
+	if _, err := stmts.deleteStats.Exec(fixture.ID); err != nil {
+		return err
+	}
+	for _, stat := range fixture.Stats {
+		if _, err := stmts.fixtureStat.Exec(fixture.ID, stat.Identifier, stat.PlayerID, stat.Home, stat.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storePlayer writes a player, their chance of playing, their snapshot for
+// the current gameweek and their history, if they were fetched with one.
+func storePlayer(stmts *statements, player models.Player, currentGameweek *models.Gameweek) error {
+	if _, err := stmts.player.Exec(playerArgs(player)...); err != nil {
+		return err
+	}
+	if _, err := stmts.deleteChances.Exec(player.ID); err != nil {
+		return err
+	}
+	for gameweekID, chance := range player.ChanceOfPlaying {
+		if _, err := stmts.playerChance.Exec(player.ID, gameweekID, chance); err != nil {
+			return err
+		}
+	}
+	if currentGameweek != nil {
+		if _, err := stmts.playerSnapshot.Exec(playerSnapshotArgs(player.Snapshot(currentGameweek.ID))...); err != nil {
+			return err
+		}
+	}
+	for _, fixture := range player.History {
+		if _, err := stmts.playerFixture.Exec(playerFixtureArgs(fixture)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storePicks replaces each manager's picks for the gameweeks being stored,
+// along with their bank.
+func storePicks(stmts *statements, picks []models.ManagerPick, gameweeks []models.ManagerGameweek) error {
+	for _, gameweek := range gameweeks {
+		if _, err := stmts.managerGameweek.Exec(gameweek.ManagerID, gameweek.GameweekID, gameweek.Bank); err != nil {
+			return err
+		}
+	}
+	type managerGameweek struct {
+		managerID  int
+		gameweekID models.GameweekID
+	}
+	cleared := make(map[managerGameweek]bool, 0)
+	for _, pick := range picks {
+		key := managerGameweek{pick.ManagerID, pick.GameweekID}
+		if !cleared[key] {
+			if _, err := stmts.deletePicks.Exec(pick.ManagerID, pick.GameweekID); err != nil {
+				return err
+			}
+			cleared[key] = true
+		}
+		if _, err := stmts.pick.Exec(pickArgs(pick)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *DataStore) StorePlayer(player models.Player) error {
+	return p.exec(insertPlayerQuery, playerArgs(player)...)
+}
+
+// StorePlayerSnapshot records a player as they stood in a gameweek, replacing
+// any earlier snapshot for that gameweek.
+func (p *DataStore) StorePlayerSnapshot(snapshot models.PlayerSnapshot) error {
+	return p.exec(insertPlayerSnapshotQuery, playerSnapshotArgs(snapshot)...)
+}
+
+func (p *DataStore) StorePlayerFixture(fixture models.PlayerFixture) error {
+	return p.exec(insertPlayerFixtureQuery, playerFixtureArgs(fixture)...)
+}
+
+func (p *DataStore) StorePlayerType(playerType models.PlayerType) error {
+	return p.exec(insertPlayerTypeQuery, playerTypeArgs(playerType)...)
+}
+
+func (p *DataStore) StoreTeam(team models.Team) error {
+	return p.exec(insertTeamQuery, teamArgs(team)...)
+}
+
+func (p *DataStore) StoreGameweek(gameweek models.Gameweek) error {
+	return p.exec(insertGameweekQuery, gameweekArgs(gameweek)...)
+}
+
+// StoreFixture writes a fixture along with its stats.
+func (p *DataStore) StoreFixture(fixture models.Fixture) error {
+	return p.inTransaction(func(tx *sql.Tx, stmts *statements) error {
+		return storeFixture(stmts, fixture)
+	})
+}
+
+func (p *DataStore) StorePick(pick models.ManagerPick) error {
+	return p.exec(insertPickQuery, pickArgs(pick)...)
+}
+
+func (p *DataStore) exec(query string, args ...any) error {
+	db, err := p.Connect()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func playerTypeArgs(playerType models.PlayerType) []any {
+	return []any{playerType.ID, playerType.Name, playerType.PluralName, playerType.ShortName, playerType.TeamPlayerCount, playerType.TeamMinPlayCount, playerType.TeamMaxPlayCount}
+}
+
+func teamArgs(team models.Team) []any {
+	return []any{team.ID, team.Name, team.ShortName}
+}
+
+func gameweekArgs(gameweek models.Gameweek) []any {
+	return []any{gameweek.ID, gameweek.Name, gameweek.Deadline, gameweek.IsCurrent, gameweek.IsNext, gameweek.Finished, gameweek.MostCaptainedID}
+}
+
+func fixtureArgs(fixture models.Fixture) []any {
+	homeScore, awayScore := fixtureScores(fixture)
+	return []any{fixture.ID, fixtureGameweekID(fixture), fixture.HomeTeam.ID, fixture.AwayTeam.ID, fixture.HomeTeamDifficulty, fixture.AwayTeamDifficulty, fixture.DifficultyMajority, fixtureKickoffTime(fixture), fixture.Started, fixture.Finished, fixture.Minutes, homeScore, awayScore}
+}
+
+// fixtureKickoffTime is the kickoff time in RFC 3339, or NULL when it isn't
+// known.
+func fixtureKickoffTime(fixture models.Fixture) sql.NullString {
+	if fixture.KickoffTime.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: fixture.KickoffTime.UTC().Format(time.RFC3339), Valid: true}
+}
+
+// fixtureScores are the fixture's scores, or NULL before it starts.
+func fixtureScores(fixture models.Fixture) (sql.NullInt64, sql.NullInt64) {
+	if !fixture.Started {
+		return sql.NullInt64{}, sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(fixture.HomeTeamScore), Valid: true}, sql.NullInt64{Int64: int64(fixture.AwayTeamScore), Valid: true}
+}
+
+// fixtureGameweekID is the fixture's gameweek ID, or NULL when it's
+// unscheduled.
+func fixtureGameweekID(fixture models.Fixture) sql.NullInt64 {
+	if !fixture.Scheduled() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(fixture.Gameweek.ID), Valid: true}
+}
+
+func playerArgs(player models.Player) []any {
+	return []any{player.ID, player.Name, player.Form, player.PointsPerGame, player.TotalPoints, player.Cost, player.RawCost, player.Team.ID, player.Type.ID, player.Stats.Minutes, player.Stats.Goals, player.Stats.Assists, player.Stats.Conceded, player.Stats.CleanSheets, player.Stats.YellowCards, player.Stats.RedCards, player.Stats.Bonus, player.Stats.Starts, player.Stats.AverageStarts, player.Stats.MatchesPlayed, player.Stats.ICTIndex, player.Stats.ICTIndexRank, player.MostCaptained, player.PickedPercentage, player.News}
+}
+
+func playerSnapshotArgs(snapshot models.PlayerSnapshot) []any {
+	return []any{snapshot.PlayerID, snapshot.GameweekID, snapshot.Form, snapshot.PointsPerGame, snapshot.TotalPoints, snapshot.Cost, snapshot.RawCost, snapshot.Stats.Minutes, snapshot.Stats.Goals, snapshot.Stats.Assists, snapshot.Stats.Conceded, snapshot.Stats.CleanSheets, snapshot.Stats.YellowCards, snapshot.Stats.RedCards, snapshot.Stats.Bonus, snapshot.Stats.Starts, snapshot.Stats.AverageStarts, snapshot.Stats.MatchesPlayed, snapshot.Stats.ICTIndex, snapshot.Stats.ICTIndexRank, snapshot.PickedPercentage, snapshot.News}
+}
+
+func playerFixtureArgs(fixture models.PlayerFixture) []any {
+	return []any{
+		fixture.FixtureID,
+		fixture.PlayerID,
+		fixture.Minutes,
+		fixture.Played,
+		fixture.Points,
+		fixture.GoalsScored,
+		fixture.Assists,
+		fixture.YellowCards,
+		fixture.RedCards,
+		fixture.Bonus,
+		fixture.CleanSheet,
+		fixture.WasHome,
+		fixture.GoalsConceded,
+		fixture.Saves,
+		fixture.PenaltiesSaved,
+		fixture.PenaltiesMissed,
+		fixture.OwnGoals,
+		fixture.DefensiveContribution,
+		fixture.GameweekID,
+		fixture.Starts,
+	}
+}
+
+func pickArgs(pick models.ManagerPick) []any {
+	return []any{pick.ManagerID, pick.GameweekID, pick.PlayerID, pick.IsCaptain, pick.IsViceCaptain}
+}
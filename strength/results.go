@@ -0,0 +1,117 @@
+package strength
+
+import (
+	"better-fantasy/models"
+	"sort"
+)
+
+// Result is a finished fixture's score.
+type Result struct {
+	FixtureID  models.FixtureID
+	GameweekID models.GameweekID
+	HomeTeamID models.TeamID
+	AwayTeamID models.TeamID
+	HomeGoals  int
+	AwayGoals  int
+}
+
+// Rate fits ratings to the finished fixtures' results, or to results rebuilt
+// from player histories when no fixture has a result stored yet.
+func Rate(players []models.Player, fixtures []*models.Fixture) *Ratings {
+	results := ResultsFromFixtures(fixtures)
+	if len(results) == 0 {
+		results = ResultsFromHistories(players)
+	}
+	return Fit(results, DefaultHalfLife)
+}
+
+// ResultsFromFixtures are the results of the finished fixtures, oldest first.
+func ResultsFromFixtures(fixtures []*models.Fixture) []Result {
+	results := make([]Result, 0)
+	for _, fixture := range fixtures {
+		homeGoals, awayGoals, ok := fixture.Result()
+		if !ok || !fixture.Scheduled() || fixture.HomeTeam == nil || fixture.AwayTeam == nil {
+			continue
+		}
+		results = append(results, Result{
+			FixtureID:  fixture.ID,
+			GameweekID: fixture.Gameweek.ID,
+			HomeTeamID: fixture.HomeTeam.ID,
+			AwayTeamID: fixture.AwayTeam.ID,
+			HomeGoals:  homeGoals,
+			AwayGoals:  awayGoals,
+		})
+	}
+	sortResults(results)
+	return results
+}
+
+// ResultsFromHistories rebuilds each played fixture's score from player
+// histories, for databases imported before fixtures had results. Each side's
+// score is the sum of its players' goals from their histories, or the goals
+// the other side's players conceded when that's more, since own goals only
+// show up there. Players are taken to have been at their current team all
+// season.
+func ResultsFromHistories(players []models.Player) []Result {
+	type side struct {
+		teamID   models.TeamID
+		scored   int
+		conceded int
+	}
+	type fixtureSides struct {
+		gameweekID models.GameweekID
+		home, away *side
+	}
+	fixtures := make(map[models.FixtureID]*fixtureSides, 0)
+	order := make([]models.FixtureID, 0)
+	for _, player := range players {
+		if player.Team == nil {
+			continue
+		}
+		for _, fixture := range player.SortedHistory() {
+			sides, ok := fixtures[fixture.FixtureID]
+			if !ok {
+				sides = &fixtureSides{gameweekID: fixture.GameweekID}
+				fixtures[fixture.FixtureID] = sides
+				order = append(order, fixture.FixtureID)
+			}
+			teamSide := &sides.away
+			if fixture.WasHome {
+				teamSide = &sides.home
+			}
+			if *teamSide == nil {
+				*teamSide = &side{teamID: player.Team.ID}
+			}
+			(*teamSide).scored += fixture.GoalsScored
+			(*teamSide).conceded = max((*teamSide).conceded, fixture.GoalsConceded)
+		}
+	}
+
+	results := make([]Result, 0, len(fixtures))
+	for _, fixtureID := range order {
+		sides := fixtures[fixtureID]
+		// both sides are needed to know who played whom
+		if sides.home == nil || sides.away == nil {
+			continue
+		}
+		results = append(results, Result{
+			FixtureID:  fixtureID,
+			GameweekID: sides.gameweekID,
+			HomeTeamID: sides.home.teamID,
+			AwayTeamID: sides.away.teamID,
+			HomeGoals:  max(sides.home.scored, sides.away.conceded),
+			AwayGoals:  max(sides.away.scored, sides.home.conceded),
+		})
+	}
+	sortResults(results)
+	return results
+}
+
+func sortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].GameweekID != results[j].GameweekID {
+			return results[i].GameweekID < results[j].GameweekID
+		}
+		return results[i].FixtureID < results[j].FixtureID
+	})
+}
@@ -0,0 +1,210 @@
+package strength
+
+import (
+	"better-fantasy/models"
+	"math"
+)
+
+const (
+	// DefaultHalfLife is how many gameweeks old a result is when it counts
+	// half as much as the latest.
+	DefaultHalfLife = 10
+	// priorMatches is how many league average matches each rating starts
+	// with, so a few freak results early on don't dominate.
+	priorMatches = 3
+	// fitIterations is how many times the ratings are refitted against each
+	// other, plenty for them to settle.
+	fitIterations = 30
+	// balanceScale turns the share of a fixture's expected goals a team
+	// scores into difficulty steps either side of 3.
+	balanceScale = 4
+)
+
+// Rating is how a team's attack and defence compare with the league average:
+// an attack of 1.2 scores 20% more than average and a defence of 0.8 concedes
+// 20% fewer.
+type Rating struct {
+	Attack  float64
+	Defence float64
+}
+
+// TeamRating is a team's rating at home and away.
+type TeamRating struct {
+	Home Rating
+	Away Rating
+	// Matches is how many results the rating is based on.
+	Matches int
+}
+
+// Ratings are every team's attack and defence at home and away, fitted to
+// results Dixon-Coles style: a fixture's goals are Poisson with a mean of the
+// league's home or away average times one side's attack and the other's
+// defence, with older results counting for less.
+type Ratings struct {
+	Teams map[models.TeamID]TeamRating
+	// HomeGoals and AwayGoals are the league's average goals per match for
+	// home and away sides.
+	HomeGoals float64
+	AwayGoals float64
+}
+
+// Fit rates every team from results, each weighted by how many gameweeks
+// before the latest it was played, halving every halfLife gameweeks.
+func Fit(results []Result, halfLife float64) *Ratings {
+	ratings := &Ratings{
+		Teams:     make(map[models.TeamID]TeamRating, 0),
+		HomeGoals: 1,
+		AwayGoals: 1,
+	}
+	if len(results) == 0 {
+		return ratings
+	}
+
+	latest := results[0].GameweekID
+	for _, result := range results {
+		latest = max(latest, result.GameweekID)
+	}
+	weights := make([]float64, len(results))
+	var totalWeight, homeGoals, awayGoals float64
+	for n, result := range results {
+		weights[n] = 1
+		if halfLife > 0 {
+			weights[n] = math.Pow(0.5, float64(latest-result.GameweekID)/halfLife)
+		}
+		totalWeight += weights[n]
+		homeGoals += weights[n] * float64(result.HomeGoals)
+		awayGoals += weights[n] * float64(result.AwayGoals)
+	}
+	if homeGoals > 0 {
+		ratings.HomeGoals = homeGoals / totalWeight
+	}
+	if awayGoals > 0 {
+		ratings.AwayGoals = awayGoals / totalWeight
+	}
+
+	for _, result := range results {
+		for _, teamID := range []models.TeamID{result.HomeTeamID, result.AwayTeamID} {
+			team, ok := ratings.Teams[teamID]
+			if !ok {
+				team = TeamRating{Home: Rating{1, 1}, Away: Rating{1, 1}}
+			}
+			team.Matches++
+			ratings.Teams[teamID] = team
+		}
+	}
+
+	// each rating is its goals over what an average side would have managed
+	// against the same opponents, starting from priorMatches average matches
+	type totals struct {
+		homeScored, homeExpectedScored, homeConceded, homeExpectedConceded float64
+		awayScored, awayExpectedScored, awayConceded, awayExpectedConceded float64
+	}
+	for iteration := 0; iteration < fitIterations; iteration++ {
+		sums := make(map[models.TeamID]*totals, len(ratings.Teams))
+		for teamID := range ratings.Teams {
+			sums[teamID] = &totals{}
+		}
+		for n, result := range results {
+			home := ratings.Teams[result.HomeTeamID]
+			away := ratings.Teams[result.AwayTeamID]
+			weight := weights[n]
+			homeSums, awaySums := sums[result.HomeTeamID], sums[result.AwayTeamID]
+			homeSums.homeScored += weight * float64(result.HomeGoals)
+			homeSums.homeExpectedScored += weight * ratings.HomeGoals * away.Away.Defence
+			homeSums.homeConceded += weight * float64(result.AwayGoals)
+			homeSums.homeExpectedConceded += weight * ratings.AwayGoals * away.Away.Attack
+			awaySums.awayScored += weight * float64(result.AwayGoals)
+			awaySums.awayExpectedScored += weight * ratings.AwayGoals * home.Home.Defence
+			awaySums.awayConceded += weight * float64(result.HomeGoals)
+			awaySums.awayExpectedConceded += weight * ratings.HomeGoals * home.Home.Attack
+		}
+
+		fitted := make(map[models.TeamID]TeamRating, len(ratings.Teams))
+		for teamID, t := range sums {
+			fitted[teamID] = TeamRating{
+				Home: Rating{
+					Attack:  shrink(t.homeScored, t.homeExpectedScored, ratings.HomeGoals),
+					Defence: shrink(t.homeConceded, t.homeExpectedConceded, ratings.AwayGoals),
+				},
+				Away: Rating{
+					Attack:  shrink(t.awayScored, t.awayExpectedScored, ratings.AwayGoals),
+					Defence: shrink(t.awayConceded, t.awayExpectedConceded, ratings.HomeGoals),
+				},
+				Matches: ratings.Teams[teamID].Matches,
+			}
+		}
+		ratings.Teams = normalise(fitted)
+	}
+	return ratings
+}
+
+// normalise scales each kind of rating to average 1, since the league
+// averages already set the overall level and the ratings would otherwise be
+// free to drift against each other.
+func normalise(teams map[models.TeamID]TeamRating) map[models.TeamID]TeamRating {
+	var homeAttack, homeDefence, awayAttack, awayDefence float64
+	for _, team := range teams {
+		homeAttack += team.Home.Attack
+		homeDefence += team.Home.Defence
+		awayAttack += team.Away.Attack
+		awayDefence += team.Away.Defence
+	}
+	count := float64(len(teams))
+	for teamID, team := range teams {
+		team.Home.Attack *= count / homeAttack
+		team.Home.Defence *= count / homeDefence
+		team.Away.Attack *= count / awayAttack
+		team.Away.Defence *= count / awayDefence
+		teams[teamID] = team
+	}
+	return teams
+}
+
+// shrink is goals over expected goals, with priorMatches matches of the
+// league average added to both.
+func shrink(goals float64, expected float64, average float64) float64 {
+	return (goals + priorMatches*average) / (expected + priorMatches*average)
+}
+
+// Team is a team's rating, the league average for a team without results.
+func (r *Ratings) Team(teamID models.TeamID) TeamRating {
+	if team, ok := r.Teams[teamID]; ok {
+		return team
+	}
+	return TeamRating{Home: Rating{1, 1}, Away: Rating{1, 1}}
+}
+
+// ExpectedGoals is how many goals each side is expected to score in a fixture.
+func (r *Ratings) ExpectedGoals(homeTeamID models.TeamID, awayTeamID models.TeamID) (float64, float64) {
+	home := r.Team(homeTeamID)
+	away := r.Team(awayTeamID)
+	return r.HomeGoals * home.Home.Attack * away.Away.Defence,
+		r.AwayGoals * away.Away.Attack * home.Home.Defence
+}
+
+// AverageGoals is the league's average goals per side per match.
+func (r *Ratings) AverageGoals() float64 {
+	return (r.HomeGoals + r.AwayGoals) / 2
+}
+
+// FixtureGoals is how many goals a team is expected to score and concede in a
+// fixture.
+func (r *Ratings) FixtureGoals(fixture *models.Fixture, teamID models.TeamID) (float64, float64) {
+	homeGoals, awayGoals := r.ExpectedGoals(fixture.HomeTeam.ID, fixture.AwayTeam.ID)
+	if fixture.HomeTeam.ID == teamID {
+		return homeGoals, awayGoals
+	}
+	return awayGoals, homeGoals
+}
+
+// Difficulty is how hard a fixture is for a team on the official 1 (easiest)
+// to 5 scale, but continuous: 3 when the team is expected to score as many as
+// it concedes, further from 3 the more lopsided the expected goals.
+func (r *Ratings) Difficulty(fixture *models.Fixture, teamID models.TeamID) float64 {
+	scored, conceded := r.FixtureGoals(fixture, teamID)
+	if scored+conceded == 0 {
+		return 3
+	}
+	balance := (scored - conceded) / (scored + conceded)
+	return math.Max(1, math.Min(5, 3-balanceScale*balance))
+}
@@ -0,0 +1,178 @@
+package ticker
+
+import (
+	"better-fantasy/models"
+	"sort"
+)
+
+const (
+	// DefaultWindow is how many gameweeks the ticker averages difficulty over
+	// and how long a run of fixtures is.
+	DefaultWindow = 3
+	// BlankDifficulty is the difficulty of a gameweek without a fixture, as
+	// bad as the hardest fixture since nobody scores.
+	BlankDifficulty = 5
+	// doubleBonus is how much easier each extra fixture in a gameweek makes
+	// it.
+	doubleBonus   = 1
+	minDifficulty = 1
+)
+
+// Difficulty rates how hard a fixture is for one of its teams, from 1
+// (easiest) to 5.
+type Difficulty func(fixture *models.Fixture, teamID models.TeamID) float32
+
+// OfficialDifficulty is the fixture's own team_h_difficulty or
+// team_a_difficulty.
+func OfficialDifficulty(fixture *models.Fixture, teamID models.TeamID) float32 {
+	if fixture.HomeTeam.ID == teamID {
+		return float32(fixture.HomeTeamDifficulty)
+	}
+	return float32(fixture.AwayTeamDifficulty)
+}
+
+// Cell is a team's fixtures in one gameweek of the ticker.
+type Cell struct {
+	GameweekID models.GameweekID
+	Fixtures   []Opponent
+	// Difficulty is the average of the fixtures' difficulty, less doubleBonus
+	// for each extra fixture, or BlankDifficulty without one.
+	Difficulty float32
+	// InBestRun is whether the gameweek is part of the team's best run.
+	InBestRun bool
+}
+
+// Opponent is one fixture from a team's side.
+type Opponent struct {
+	Team       *models.Team
+	Home       bool
+	Difficulty float32
+}
+
+// Run is a stretch of consecutive gameweeks.
+type Run struct {
+	From       models.GameweekID
+	To         models.GameweekID
+	Difficulty float32
+}
+
+// Row is a team's line of the ticker.
+type Row struct {
+	Team  *models.Team
+	Cells []Cell
+	// Difficulty is the average difficulty over the first window gameweeks,
+	// which the ticker is sorted by.
+	Difficulty float32
+	// BestRun is the window gameweeks in a row with the lowest average
+	// difficulty, the earliest when they tie.
+	BestRun Run
+}
+
+// Build lays out every team's fixtures from one gameweek to another
+// (inclusive), rated by difficulty, easiest first by average difficulty over
+// the first window gameweeks.
+func Build(teams []*models.Team, fixtures []*models.Fixture, fromGameweek models.GameweekID, toGameweek models.GameweekID, window int, difficulty Difficulty) []Row {
+	window = Window(window, int(toGameweek-fromGameweek)+1)
+	opponents := make(map[models.TeamID]map[models.GameweekID][]Opponent, len(teams))
+	addOpponent := func(team *models.Team, gameweekID models.GameweekID, opponent Opponent) {
+		if opponents[team.ID] == nil {
+			opponents[team.ID] = make(map[models.GameweekID][]Opponent, 0)
+		}
+		opponents[team.ID][gameweekID] = append(opponents[team.ID][gameweekID], opponent)
+	}
+	for _, fixture := range fixtures {
+		if !fixture.Scheduled() || fixture.HomeTeam == nil || fixture.AwayTeam == nil {
+			continue
+		}
+		gameweekID := fixture.Gameweek.ID
+		addOpponent(fixture.HomeTeam, gameweekID, Opponent{Team: fixture.AwayTeam, Home: true, Difficulty: difficulty(fixture, fixture.HomeTeam.ID)})
+		addOpponent(fixture.AwayTeam, gameweekID, Opponent{Team: fixture.HomeTeam, Home: false, Difficulty: difficulty(fixture, fixture.AwayTeam.ID)})
+	}
+
+	rows := make([]Row, 0, len(teams))
+	for _, team := range teams {
+		row := Row{
+			Team:  team,
+			Cells: make([]Cell, 0),
+		}
+		for gameweekID := fromGameweek; gameweekID <= toGameweek; gameweekID++ {
+			cell := Cell{
+				GameweekID: gameweekID,
+				Fixtures:   opponents[team.ID][gameweekID],
+			}
+			cell.Difficulty = gameweekDifficulty(cell.Fixtures)
+			row.Cells = append(row.Cells, cell)
+		}
+		row.Difficulty = averageDifficulty(row.Cells, 0, window)
+		row.BestRun = bestRun(row.Cells, window)
+		for n := range row.Cells {
+			if row.Cells[n].GameweekID >= row.BestRun.From && row.Cells[n].GameweekID <= row.BestRun.To {
+				row.Cells[n].InBestRun = true
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Difficulty < rows[j].Difficulty
+	})
+	return rows
+}
+
+// Window is window limited to the gameweeks in the ticker, all of them when
+// it's not positive.
+func Window(window int, gameweeks int) int {
+	if window < 1 || window > gameweeks {
+		return gameweeks
+	}
+	return window
+}
+
+func gameweekDifficulty(opponents []Opponent) float32 {
+	if len(opponents) == 0 {
+		return BlankDifficulty
+	}
+	var total float32
+	for _, opponent := range opponents {
+		total += opponent.Difficulty
+	}
+	difficulty := total/float32(len(opponents)) - float32(doubleBonus*(len(opponents)-1))
+	if difficulty < minDifficulty {
+		return minDifficulty
+	}
+	return difficulty
+}
+
+// averageDifficulty averages up to window cells from start.
+func averageDifficulty(cells []Cell, start int, window int) float32 {
+	end := start + window
+	if end > len(cells) {
+		end = len(cells)
+	}
+	if end <= start {
+		return 0
+	}
+	var total float32
+	for _, cell := range cells[start:end] {
+		total += cell.Difficulty
+	}
+	return total / float32(end-start)
+}
+
+func bestRun(cells []Cell, window int) Run {
+	if len(cells) == 0 {
+		return Run{}
+	}
+	var best Run
+	for start := 0; start+window <= len(cells); start++ {
+		difficulty := averageDifficulty(cells, start, window)
+		if start == 0 || difficulty < best.Difficulty {
+			best = Run{
+				From:       cells[start].GameweekID,
+				To:         cells[start+window-1].GameweekID,
+				Difficulty: difficulty,
+			}
+		}
+	}
+	return best
+}
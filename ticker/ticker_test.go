@@ -0,0 +1,76 @@
+package ticker
+
+import (
+	"better-fantasy/models"
+	"math"
+	"testing"
+)
+
+// cells lays out a gameweek for each difficulty, starting from gameweek 1.
+func cells(difficulties ...float32) []Cell {
+	laidOut := make([]Cell, 0, len(difficulties))
+	for n, difficulty := range difficulties {
+		laidOut = append(laidOut, Cell{GameweekID: models.GameweekID(n + 1), Difficulty: difficulty})
+	}
+	return laidOut
+}
+
+func TestBestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		cells  []Cell
+		window int
+		want   Run
+	}{
+		{
+			name:   "run at the start",
+			cells:  cells(1, 1, 3, 4, 5),
+			window: 2,
+			want:   Run{From: 1, To: 2, Difficulty: 1},
+		},
+		{
+			name:   "run at the end",
+			cells:  cells(4, 4, 3, 1, 1),
+			window: 2,
+			want:   Run{From: 4, To: 5, Difficulty: 1},
+		},
+		{
+			name:   "blank gameweek breaks up a run",
+			cells:  cells(2, BlankDifficulty, 2, 2, 3),
+			window: 2,
+			want:   Run{From: 3, To: 4, Difficulty: 2},
+		},
+		{
+			name:   "run including a blank gameweek",
+			cells:  cells(BlankDifficulty, BlankDifficulty, 1, BlankDifficulty, 1, 4, 4),
+			window: 3,
+			want:   Run{From: 3, To: 5, Difficulty: 7.0 / 3},
+		},
+		{
+			name:   "earliest of tied runs",
+			cells:  cells(2, 2, 2, 2),
+			window: 2,
+			want:   Run{From: 1, To: 2, Difficulty: 2},
+		},
+		{
+			name:   "window as long as the horizon",
+			cells:  cells(1, 3, 5),
+			window: 3,
+			want:   Run{From: 1, To: 3, Difficulty: 3},
+		},
+		{
+			name:   "no gameweeks",
+			cells:  cells(),
+			window: 3,
+			want:   Run{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := bestRun(test.cells, test.window)
+			if got.From != test.want.From || got.To != test.want.To || math.Abs(float64(got.Difficulty-test.want.Difficulty)) > 1e-5 {
+				t.Errorf("bestRun() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}